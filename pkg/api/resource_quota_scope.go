@@ -0,0 +1,33 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceQuotaScope narrows a ResourceQuota to only count the pods matching it, so e.g. a
+// quota's CPU limit can apply to long-running workloads without also constraining batch jobs.
+type ResourceQuotaScope string
+
+const (
+	// ResourceQuotaScopeBestEffort matches pods with no CPU or memory request or limit at all.
+	ResourceQuotaScopeBestEffort ResourceQuotaScope = "BestEffort"
+	// ResourceQuotaScopeNotBestEffort matches pods that declare any CPU or memory request or
+	// limit.
+	ResourceQuotaScopeNotBestEffort ResourceQuotaScope = "NotBestEffort"
+	// ResourceQuotaScopeTerminating matches pods with a Spec.ActiveDeadlineSeconds set.
+	ResourceQuotaScopeTerminating ResourceQuotaScope = "Terminating"
+	// ResourceQuotaScopeNotTerminating matches pods with no Spec.ActiveDeadlineSeconds set.
+	ResourceQuotaScopeNotTerminating ResourceQuotaScope = "NotTerminating"
+)
@@ -0,0 +1,40 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceControllerGroupByDaemonSet groups resource usage by DaemonSets in a namespace, letting a
+// ResourceController cap how many DaemonSets (and, transitively, the pods they imply across the
+// cluster's nodes) a namespace may create.
+const ResourceControllerGroupByDaemonSet ResourceControllerGroupBy = "DaemonSet"
+
+// ResourceControllerGroupByService groups resource usage by Services in a namespace, letting a
+// ResourceController cap the count of Services, LoadBalancer-typed Services, and Services exposing
+// a NodePort independently of the coarser per-namespace Services count.
+const ResourceControllerGroupByService ResourceControllerGroupBy = "Service"
+
+// ResourceControllerGroupByPersistentVolumeClaim groups resource usage by PersistentVolumeClaims in
+// a namespace, letting a ResourceController cap both the count of claims and the aggregate storage
+// they request.
+const ResourceControllerGroupByPersistentVolumeClaim ResourceControllerGroupBy = "PersistentVolumeClaim"
+
+// ResourceControllerGroupBySecret groups resource usage by Secrets in a namespace, letting a
+// ResourceController cap both the count of Secrets and their aggregate encoded size.
+const ResourceControllerGroupBySecret ResourceControllerGroupBy = "Secret"
+
+// ResourceControllerGroupByConfigMap groups resource usage by ConfigMaps in a namespace, letting a
+// ResourceController cap both the count of ConfigMaps and their aggregate encoded size.
+const ResourceControllerGroupByConfigMap ResourceControllerGroupBy = "ConfigMap"
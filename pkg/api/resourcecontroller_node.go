@@ -0,0 +1,25 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceControllerGroupByNode groups resource usage by the node a pod is bound to, allowing
+// a ResourceController to enforce per-node allocatable accounting instead of per-namespace counts.
+const ResourceControllerGroupByNode ResourceControllerGroupBy = "Node"
+
+// ResourceControllerRuleTypeRequest constrains the aggregate of container resource requests,
+// as distinct from ResourceControllerRuleTypeMax which constrains limits/overcommit.
+const ResourceControllerRuleTypeRequest ResourceControllerRuleType = "Request"
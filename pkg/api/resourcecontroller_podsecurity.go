@@ -0,0 +1,23 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceControllerRuleTypePodSecurityProfile names the rule type whose Resources hold the
+// configured pod-security profile for a group, rather than a resource quantity. The profile in
+// effect is named by the single ResourceName key present (e.g. "Baseline" or "Restricted"); the
+// quantity associated with that key is unused and by convention set to 1.
+const ResourceControllerRuleTypePodSecurityProfile ResourceControllerRuleType = "PodSecurityProfile"
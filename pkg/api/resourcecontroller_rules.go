@@ -0,0 +1,31 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceControllerRuleTypeRatio constrains a container's CPU/Memory quantity to at most a
+// configured fraction of the same group's RuleTypeMax ceiling for that resource, letting a
+// ResourceController allow routine containers well under Max while still capping how close any
+// single container may get to it (e.g. a ratio of "500m" forbids a container from using more than
+// half of the group's Max). This API generation models a single CPU/Memory quantity per container
+// rather than separate request/limit quantities (see the namespace admission func's comment on
+// AllowedAndAllocatedForNode), so Ratio is expressed against Max instead of a limit-to-request
+// ratio. The quantity is carried as MilliValue so a configured ratio like "1500m" means 1.5x.
+const ResourceControllerRuleTypeRatio ResourceControllerRuleType = "Ratio"
+
+// ResourceControllerRuleTypeDefault carries the CPU/Memory quantity a container is defaulted to
+// when the incoming pod omits it, rather than a value enforced against what's already present.
+const ResourceControllerRuleTypeDefault ResourceControllerRuleType = "Default"
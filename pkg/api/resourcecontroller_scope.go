@@ -0,0 +1,33 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ResourceControllerScope narrows a ResourceController's Spec.Allowed groups to only count the
+// pods matching it, so e.g. a namespace can run separate ResourceControllers for its batch,
+// best-effort workloads and its long-running, Guaranteed services.
+type ResourceControllerScope string
+
+const (
+	// ResourceControllerScopeBestEffort matches pods whose containers declare no CPU or Memory at all.
+	ResourceControllerScopeBestEffort ResourceControllerScope = "BestEffort"
+	// ResourceControllerScopeNotBestEffort matches pods that declare any CPU or Memory.
+	ResourceControllerScopeNotBestEffort ResourceControllerScope = "NotBestEffort"
+	// ResourceControllerScopeTerminating matches pods with a Spec.ActiveDeadlineSeconds set.
+	ResourceControllerScopeTerminating ResourceControllerScope = "Terminating"
+	// ResourceControllerScopeNotTerminating matches pods with no Spec.ActiveDeadlineSeconds set.
+	ResourceControllerScopeNotTerminating ResourceControllerScope = "NotTerminating"
+)
@@ -54,7 +54,12 @@ func (c *FakeResourceControllers) Delete(controller string) error {
 	return nil
 }
 
-func (c *FakeResourceControllers) Watch(label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
-	c.Fake.Actions = append(c.Fake.Actions, FakeAction{Action: "watch-controllers", Value: resourceVersion})
+func (c *FakeResourceControllers) Watch(options ResourceControllerWatchOptions) (watch.Interface, error) {
+	c.Fake.Actions = append(c.Fake.Actions, FakeAction{Action: "watch-controllers", Value: options.ResourceVersion})
 	return c.Fake.Watch, nil
 }
+
+func (c *FakeResourceControllers) Scale(name string, scale *api.Scale) (*api.Scale, error) {
+	c.Fake.Actions = append(c.Fake.Actions, FakeAction{Action: "scale-controller", Value: name})
+	return &api.Scale{}, nil
+}
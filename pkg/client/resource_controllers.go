@@ -18,6 +18,7 @@ package client
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
@@ -36,7 +37,27 @@ type ResourceControllerInterface interface {
 	Create(ctrl *api.ResourceController) (*api.ResourceController, error)
 	Update(ctrl *api.ResourceController) (*api.ResourceController, error)
 	Delete(name string) error
-	Watch(label, field labels.Selector, resourceVersion string) (watch.Interface, error)
+	Watch(options ResourceControllerWatchOptions) (watch.Interface, error)
+	// Scale updates the named resource controller's scale subresource, so a caller such as an
+	// HPA-style controller can adjust spec.replicas atomically without reading and rewriting the
+	// full controller object.
+	Scale(name string, scale *api.Scale) (*api.Scale, error)
+}
+
+// ResourceControllerWatchOptions carries the parameters of a ResourceControllerInterface.Watch
+// call.
+type ResourceControllerWatchOptions struct {
+	// LabelSelector restricts the watch to resource controllers matching this label selector.
+	LabelSelector labels.Selector
+	// FieldSelector restricts the watch to resource controllers matching this field selector.
+	FieldSelector labels.Selector
+	// ResourceVersion is the resource version to watch from.
+	ResourceVersion string
+	// Bookmark requests that the server periodically emit a bookmark event carrying only an
+	// updated resourceVersion, so a watcher can persist its position without having seen a recent
+	// real event. The server for this API predates bookmark events, so setting it is a no-op
+	// until the server side gains the same support.
+	Bookmark bool
 }
 
 // resourceControllers implements ResourceControllersNamespacer interface
@@ -88,13 +109,25 @@ func (c *resourceControllers) Delete(name string) error {
 }
 
 // Watch returns a watch.Interface that watches the requested controllers.
-func (c *resourceControllers) Watch(label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+func (c *resourceControllers) Watch(options ResourceControllerWatchOptions) (watch.Interface, error) {
 	return c.r.Get().
 		Prefix("watch").
 		Namespace(c.ns).
 		Resource("resourceControllers").
-		Param("resourceVersion", resourceVersion).
-		SelectorParam("labels", label).
-		SelectorParam("fields", field).
+		Param("resourceVersion", options.ResourceVersion).
+		Param("bookmark", strconv.FormatBool(options.Bookmark)).
+		SelectorParam("labels", options.LabelSelector).
+		SelectorParam("fields", options.FieldSelector).
 		Watch()
 }
+
+// Scale updates the named resource controller's scale subresource and returns the result.
+func (c *resourceControllers) Scale(name string, scale *api.Scale) (result *api.Scale, err error) {
+	result = &api.Scale{}
+	if len(scale.ResourceVersion) == 0 {
+		err = fmt.Errorf("invalid scale object, missing resource version: %v", scale)
+		return
+	}
+	err = c.r.Put().Namespace(c.ns).Resource("resourceControllers").Name(name).SubResource("scale").Body(scale).Do().Into(result)
+	return
+}
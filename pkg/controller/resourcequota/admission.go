@@ -0,0 +1,251 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/admission"
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+const (
+	// maxQuotaUpdateRetries bounds how many times quotaAdmission retries an optimistic Status.Used
+	// update that lost a race, before giving up and denying the request.
+	maxQuotaUpdateRetries = 5
+	// quotaUpdateRetryBackoff is the delay before the first retry; it doubles on each subsequent
+	// attempt.
+	quotaUpdateRetryBackoff = 10 * time.Millisecond
+)
+
+func init() {
+	admission.RegisterPlugin("ResourceQuota", func(kubeClient client.Interface, config io.Reader) (admission.Interface, error) {
+		usageRegistry, err := usageRegistryFromConfig(kubeClient, config)
+		if err != nil {
+			return nil, err
+		}
+		return NewQuotaAdmission(kubeClient, usageRegistry, NewMonitoringControllerFactory(kubeClient))
+	})
+}
+
+// usageRegistryFromConfig builds the UsageFuncRegistry the plugin charges quota against: the
+// built-in registry for Pods/Services/RCs/PVCs, composed with a webhook-backed registry for any
+// additional GroupKinds named in config. config, if non-empty, is a WebhookUsageConfig in JSON or
+// YAML, the same shape LoadWebhookUsageConfig reads from a file; an empty or absent config leaves
+// the built-in registry as the only one consulted.
+func usageRegistryFromConfig(kubeClient client.Interface, config io.Reader) (UsageFuncRegistry, error) {
+	defaultRegistry := NewDefaultUsageFuncRegistry(kubeClient)
+	if config == nil {
+		return defaultRegistry, nil
+	}
+	data, err := ioutil.ReadAll(config)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ResourceQuota admission plugin config: %v", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return defaultRegistry, nil
+	}
+
+	webhookConfig, err := ParseWebhookUsageConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ResourceQuota admission plugin config: %v", err)
+	}
+	webhookRegistry, err := NewWebhookUsageRegistry(webhookConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompositeUsageFuncRegistry(defaultRegistry, webhookRegistry), nil
+}
+
+// quotaAdmission charges a Pod (or other monitored object) against every ResourceQuota in its
+// namespace synchronously, on Create and Update, so a burst of creations can't blow past
+// Status.Hard in the window before ResourceQuotaController's next resync.
+type quotaAdmission struct {
+	kubeClient    client.Interface
+	usageRegistry UsageFuncRegistry
+	cache         *quotaCache
+}
+
+// NewQuotaAdmission returns an admission.Interface that charges usageRegistry's UsageFuncs against
+// quota synchronously. factory, if non-nil, is used to invalidate the plugin's quota cache
+// whenever the shared informers it drives observe a ResourceQuota change, instead of the plugin
+// starting its own watch.
+func NewQuotaAdmission(kubeClient client.Interface, usageRegistry UsageFuncRegistry, factory ControllerFactory) (admission.Interface, error) {
+	cache := newQuotaCache(kubeClient)
+	if factory != nil {
+		if err := factory.NewController(unversioned.GroupKind{Kind: "ResourceQuota"}, cache.invalidate); err != nil {
+			return nil, err
+		}
+	}
+	return &quotaAdmission{
+		kubeClient:    kubeClient,
+		usageRegistry: usageRegistry,
+		cache:         cache,
+	}, nil
+}
+
+func (q *quotaAdmission) Handles(operation admission.Operation) bool {
+	return operation == admission.Create || operation == admission.Update
+}
+
+func (q *quotaAdmission) Admit(a admission.Attributes) error {
+	if !q.Handles(a.GetOperation()) {
+		return nil
+	}
+	// a subresource request (e.g. Pod/status, Pod/binding) doesn't change the object's resource
+	// footprint, so it never needs to be charged against quota.
+	if len(a.GetSubresource()) != 0 {
+		return nil
+	}
+
+	object := a.GetObject()
+	if object == nil {
+		return nil
+	}
+
+	groupKind := a.GetKind().GroupKind()
+	usageFunc, found := q.usageRegistry.UsageFunc(groupKind)
+	if !found {
+		return nil
+	}
+
+	delta, err := usageFunc(object)
+	if err != nil || len(delta) == 0 {
+		return nil
+	}
+
+	quotas, err := q.cache.list(a.GetNamespace())
+	if err != nil {
+		return apierrors.NewForbidden(a.GetResource().GroupResource(), a.GetName(),
+			fmt.Errorf("unable to %s %s at this time because quotas could not be listed: %v", a.GetOperation(), groupKind, err))
+	}
+
+	// charged tracks which quotas this call actually incremented, in order, so that if a later
+	// quota in the namespace denies the request we can undo the earlier increments instead of
+	// leaving their Status.Used permanently drifted from reality.
+	charged := make([]*api.ResourceQuota, 0, len(quotas))
+	for i := range quotas {
+		chargedQuota, err := q.chargeQuota(&quotas[i], a, groupKind, object, delta)
+		if err != nil {
+			q.rollbackCharges(charged, a, delta)
+			return err
+		}
+		if chargedQuota != nil {
+			charged = append(charged, chargedQuota)
+		}
+	}
+	return nil
+}
+
+// chargeQuota adds delta to quota's Status.Used, retrying the optimistic Update on a conflict, and
+// denies the request instead if doing so would push any resource over quota's Spec.Hard. The
+// returned quota is quota as charged (nil if it was skipped, e.g. because the object falls outside
+// quota.Spec.Scopes), so the caller can roll that charge back later.
+func (q *quotaAdmission) chargeQuota(quota *api.ResourceQuota, a admission.Attributes, groupKind unversioned.GroupKind, object runtime.Object, delta api.ResourceList) (*api.ResourceQuota, error) {
+	if len(quota.Spec.Hard) == 0 {
+		return nil, nil
+	}
+	if pod, ok := object.(*api.Pod); ok && groupKind.Kind == "Pod" && !PodMatchesScopes(pod, quota.Spec.Scopes) {
+		return nil, nil
+	}
+
+	return q.applyDelta(quota, a, delta, true, false)
+}
+
+// rollbackCharges undoes a previously-applied delta against every quota in charged, best-effort:
+// a rollback failure is logged rather than surfaced, since the Admit call has already decided to
+// deny the request and returning a different error here would only obscure the original reason.
+func (q *quotaAdmission) rollbackCharges(charged []*api.ResourceQuota, a admission.Attributes, delta api.ResourceList) {
+	for _, quota := range charged {
+		if _, err := q.applyDelta(quota, a, delta, false, true); err != nil {
+			glog.Errorf("Unable to roll back quota %s/%s usage after a later quota denied the request: %v", quota.Namespace, quota.Name, err)
+		}
+	}
+}
+
+// applyDelta adjusts quota's Status.Used by delta, retrying the optimistic Update on a conflict.
+// When enforceHard is true, it denies the request instead of persisting the update if doing so
+// would push any resource over quota's Spec.Hard; a rollback's compensating update always passes
+// enforceHard=false, since undoing an earlier charge can only reduce Status.Used. subtract reverses
+// delta's effect (used for rollback) instead of applying it (used for the original charge). On
+// success it returns the quota as updated.
+func (q *quotaAdmission) applyDelta(quota *api.ResourceQuota, a admission.Attributes, delta api.ResourceList, enforceHard, subtract bool) (*api.ResourceQuota, error) {
+	current := quota
+	backoff := quotaUpdateRetryBackoff
+	for attempt := 0; ; attempt++ {
+		newUsed := api.ResourceList{}
+		for name, value := range current.Status.Used {
+			newUsed[name] = value
+		}
+
+		var exceeded []string
+		for resourceName, deltaValue := range delta {
+			hard, limited := current.Spec.Hard[resourceName]
+			if !limited {
+				continue
+			}
+			used := newUsed[resourceName]
+			if subtract {
+				used.Sub(deltaValue)
+			} else {
+				used.Add(deltaValue)
+			}
+			newUsed[resourceName] = used
+			if enforceHard && used.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s requested %s, used %s of %s limit", resourceName, deltaValue.String(), current.Status.Used[resourceName].String(), hard.String()))
+			}
+		}
+		if len(exceeded) > 0 {
+			sort.Strings(exceeded)
+			return nil, apierrors.NewForbidden(a.GetResource().GroupResource(), a.GetName(),
+				fmt.Errorf("exceeded quota %s: %s", current.Name, strings.Join(exceeded, "; ")))
+		}
+
+		updated := *current
+		updated.Status.Used = newUsed
+		_, err := q.kubeClient.ResourceQuotas(updated.Namespace).Update(&updated)
+		if err == nil {
+			return &updated, nil
+		}
+		if !apierrors.IsConflict(err) || attempt >= maxQuotaUpdateRetries {
+			return nil, apierrors.NewForbidden(a.GetResource().GroupResource(), a.GetName(),
+				fmt.Errorf("unable to update quota %s usage: %v", current.Name, err))
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+
+		latest, getErr := q.kubeClient.ResourceQuotas(current.Namespace).Get(current.Name)
+		if getErr != nil {
+			return nil, apierrors.NewForbidden(a.GetResource().GroupResource(), a.GetName(),
+				fmt.Errorf("unable to refresh quota %s after conflict: %v", current.Name, getErr))
+		}
+		current = latest
+	}
+}
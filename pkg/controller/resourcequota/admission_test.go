@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/admission"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/user"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving quotaAdmission.Admit in tests,
+// standing in for the real admission.NewAttributesRecord since this tree doesn't vendor the
+// upstream admission package.
+type fakeAttributes struct {
+	namespace string
+	name      string
+	resource  unversioned.GroupVersionResource
+	kind      unversioned.GroupVersionKind
+	operation admission.Operation
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetName() string                               { return f.name }
+func (f fakeAttributes) GetNamespace() string                          { return f.namespace }
+func (f fakeAttributes) GetResource() unversioned.GroupVersionResource { return f.resource }
+func (f fakeAttributes) GetSubresource() string                        { return "" }
+func (f fakeAttributes) GetOperation() admission.Operation             { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object                     { return f.object }
+func (f fakeAttributes) GetOldObject() runtime.Object                  { return nil }
+func (f fakeAttributes) GetKind() unversioned.GroupVersionKind         { return f.kind }
+func (f fakeAttributes) GetUserInfo() user.Info                        { return nil }
+
+func newServiceAttributes(namespace, name string) admission.Attributes {
+	return fakeAttributes{
+		namespace: namespace,
+		name:      name,
+		resource:  unversioned.GroupVersionResource{Resource: "services"},
+		kind:      unversioned.GroupVersionKind{Kind: "Service"},
+		operation: admission.Create,
+		object:    &api.Service{ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: name}},
+	}
+}
+
+// TestAdmitRollsBackEarlierChargesOnDeny verifies that when a namespace has more than one
+// ResourceQuota and a later quota in the list denies the request, any quota already charged
+// earlier in the same Admit call is rolled back rather than left permanently incremented.
+func TestAdmitRollsBackEarlierChargesOnDeny(t *testing.T) {
+	const namespace = "test-ns"
+
+	roomyQuota := api.ResourceQuota{
+		ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: "roomy"},
+		Spec: api.ResourceQuotaSpec{
+			Hard: api.ResourceList{api.ResourceServices: *resource.NewQuantity(5, resource.DecimalSI)},
+		},
+		Status: api.ResourceQuotaStatus{
+			Hard: api.ResourceList{api.ResourceServices: *resource.NewQuantity(5, resource.DecimalSI)},
+			Used: api.ResourceList{api.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI)},
+		},
+	}
+	tightQuota := api.ResourceQuota{
+		ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: "tight"},
+		Spec: api.ResourceQuotaSpec{
+			Hard: api.ResourceList{api.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI)},
+		},
+		Status: api.ResourceQuotaStatus{
+			Hard: api.ResourceList{api.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI)},
+			Used: api.ResourceList{api.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI)},
+		},
+	}
+
+	kubeClient := testclient.NewSimpleFake(&api.ResourceQuotaList{Items: []api.ResourceQuota{roomyQuota, tightQuota}})
+	q, err := NewQuotaAdmission(kubeClient, NewDefaultUsageFuncRegistry(kubeClient), nil)
+	if err != nil {
+		t.Fatalf("unexpected error constructing quotaAdmission: %v", err)
+	}
+
+	err = q.Admit(newServiceAttributes(namespace, "new-svc"))
+	if err == nil {
+		t.Fatalf("expected Admit to deny the request once the tight quota is exceeded")
+	}
+
+	var sawRollback bool
+	for _, action := range kubeClient.Actions() {
+		update, ok := action.(testclient.UpdateAction)
+		if !ok {
+			continue
+		}
+		quota, ok := update.GetObject().(*api.ResourceQuota)
+		if !ok || quota.Name != roomyQuota.Name {
+			continue
+		}
+		if used := quota.Status.Used[api.ResourceServices]; used.Value() == roomyQuota.Status.Used[api.ResourceServices].Value() {
+			sawRollback = true
+		}
+	}
+	if !sawRollback {
+		t.Errorf("expected a compensating update restoring quota %q's Status.Used back to %v after the deny, found none in %#v", roomyQuota.Name, roomyQuota.Status.Used, kubeClient.Actions())
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// compositeUsageFuncRegistry tries each of its member registries in order, returning the first
+// UsageFunc found. This lets a webhook-backed registry for custom resources sit alongside the
+// built-in registry for Pods/Services/RCs/PVCs without either one needing to know about the other.
+type compositeUsageFuncRegistry struct {
+	registries []UsageFuncRegistry
+}
+
+// NewCompositeUsageFuncRegistry returns a UsageFuncRegistry that consults registries in order,
+// preferring earlier ones when more than one resolves the same GroupKind.
+func NewCompositeUsageFuncRegistry(registries ...UsageFuncRegistry) UsageFuncRegistry {
+	return &compositeUsageFuncRegistry{registries: registries}
+}
+
+func (r *compositeUsageFuncRegistry) UsageFunc(groupKind unversioned.GroupKind) (UsageFunc, bool) {
+	for _, registry := range r.registries {
+		if f, found := registry.UsageFunc(groupKind); found {
+			return f, true
+		}
+	}
+	return nil, false
+}
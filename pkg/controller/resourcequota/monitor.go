@@ -0,0 +1,200 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+// defaultMonitorResyncPeriod is how often monitoringControllerFactory polls a monitored GroupKind
+// for changes, standing in for a real watch against this repo's List-only client.Interface — the
+// same simplification pkg/resourcecontroller's namespaceInformer makes for the sibling subsystem.
+const defaultMonitorResyncPeriod = 30 * time.Second
+
+// DynamicGroupKindRegisterer is implemented by a ControllerFactory that can be told about
+// additional GroupKinds to watch after construction, e.g. ones a webhook usage config discovers
+// at load time. It's a separate interface from ControllerFactory, rather than an added method on
+// it, because not every factory implementation needs to support being extended dynamically;
+// callers type-assert for it.
+type DynamicGroupKindRegisterer interface {
+	RegisterGroupKind(groupKind unversioned.GroupKind)
+}
+
+// monitoringControllerFactory builds the per-GroupKind watch that keeps the ResourceQuotaController
+// aware of changes to the objects it charges against quota.
+type monitoringControllerFactory struct {
+	kubeClient   client.Interface
+	resyncPeriod time.Duration
+
+	lock              sync.RWMutex
+	dynamicGroupKinds sets.String
+}
+
+// NewMonitoringControllerFactory returns a ControllerFactory that polls a GroupKind's objects
+// through kubeClient every resync period and invokes resyncFunc once for each poll that sees an
+// add, update, or delete since the previous one. It also implements
+// DynamicGroupKindRegisterer, so GroupKinds declared by a webhook usage config loaded after
+// construction can still be watched.
+func NewMonitoringControllerFactory(kubeClient client.Interface) ControllerFactory {
+	return &monitoringControllerFactory{
+		kubeClient:        kubeClient,
+		resyncPeriod:      defaultMonitorResyncPeriod,
+		dynamicGroupKinds: sets.NewString(),
+	}
+}
+
+// RegisterGroupKind adds groupKind to the set NewController will accept, in addition to the
+// built-in Pod/Service/ReplicationController/PersistentVolumeClaim kinds.
+func (f *monitoringControllerFactory) RegisterGroupKind(groupKind unversioned.GroupKind) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.dynamicGroupKinds.Insert(groupKind.String())
+}
+
+func (f *monitoringControllerFactory) isDynamicallyRegistered(groupKind unversioned.GroupKind) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.dynamicGroupKinds.Has(groupKind.String())
+}
+
+// NewController is only responsible for starting a GroupKind's watch; it's not expected to block,
+// so the watch runs on its own goroutine for the lifetime of the process.
+func (f *monitoringControllerFactory) NewController(groupKind unversioned.GroupKind, resyncFunc func()) error {
+	lister, ok := f.listerFor(groupKind)
+	if !ok {
+		if f.isDynamicallyRegistered(groupKind) {
+			// A dynamically registered GroupKind comes from a webhook usage config; the webhook
+			// is responsible for noticing its own objects change, so there's nothing to poll here.
+			return nil
+		}
+		return fmt.Errorf("no monitor available for GroupKind %v", groupKind)
+	}
+	go f.poll(groupKind, lister, resyncFunc)
+	return nil
+}
+
+// listerFor returns the function that lists every object of groupKind across all namespaces, or
+// false if groupKind isn't one of the built-in kinds this factory knows how to poll.
+func (f *monitoringControllerFactory) listerFor(groupKind unversioned.GroupKind) (func() (map[string]string, error), bool) {
+	switch groupKind.Kind {
+	case "Pod":
+		return func() (map[string]string, error) {
+			list, err := f.kubeClient.Pods(api.NamespaceAll).List(labels.Everything(), fields.Everything())
+			if err != nil {
+				return nil, err
+			}
+			signatures := make(map[string]string, len(list.Items))
+			for _, pod := range list.Items {
+				signatures[pod.Namespace+"/"+pod.Name] = pod.ResourceVersion
+			}
+			return signatures, nil
+		}, true
+	case "Service":
+		return func() (map[string]string, error) {
+			list, err := f.kubeClient.Services(api.NamespaceAll).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			signatures := make(map[string]string, len(list.Items))
+			for _, service := range list.Items {
+				signatures[service.Namespace+"/"+service.Name] = service.ResourceVersion
+			}
+			return signatures, nil
+		}, true
+	case "ReplicationController":
+		return func() (map[string]string, error) {
+			list, err := f.kubeClient.ReplicationControllers(api.NamespaceAll).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			signatures := make(map[string]string, len(list.Items))
+			for _, rc := range list.Items {
+				signatures[rc.Namespace+"/"+rc.Name] = rc.ResourceVersion
+			}
+			return signatures, nil
+		}, true
+	case "PersistentVolumeClaim":
+		return func() (map[string]string, error) {
+			list, err := f.kubeClient.PersistentVolumeClaims(api.NamespaceAll).List(labels.Everything(), fields.Everything())
+			if err != nil {
+				return nil, err
+			}
+			signatures := make(map[string]string, len(list.Items))
+			for _, claim := range list.Items {
+				signatures[claim.Namespace+"/"+claim.Name] = claim.ResourceVersion
+			}
+			return signatures, nil
+		}, true
+	case "ResourceQuota":
+		return func() (map[string]string, error) {
+			list, err := f.kubeClient.ResourceQuotas(api.NamespaceAll).List(labels.Everything(), fields.Everything())
+			if err != nil {
+				return nil, err
+			}
+			signatures := make(map[string]string, len(list.Items))
+			for _, quota := range list.Items {
+				signatures[quota.Namespace+"/"+quota.Name] = quota.ResourceVersion
+			}
+			return signatures, nil
+		}, true
+	}
+	return nil, false
+}
+
+// poll periodically calls lister and invokes resyncFunc whenever the (namespace, name,
+// resourceVersion) tuples it returns differ from the previous poll, mimicking a watch without
+// requiring this package's client.Interface to support one directly. The first poll only seeds
+// the baseline: resyncFunc isn't called until a second poll observes a change against it.
+func (f *monitoringControllerFactory) poll(groupKind unversioned.GroupKind, lister func() (map[string]string, error), resyncFunc func()) {
+	var previous map[string]string
+	wait.Until(func() {
+		current, err := lister()
+		if err != nil {
+			glog.Errorf("resource quota monitor: error listing %v: %v", groupKind, err)
+			return
+		}
+		if previous != nil && !signaturesEqual(previous, current) {
+			resyncFunc()
+		}
+		previous = current
+	}, f.resyncPeriod, wait.NeverStop)
+}
+
+// signaturesEqual reports whether a and b, maps of object key to resourceVersion, are identical.
+func signaturesEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, version := range a {
+		if b[key] != version {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"sync"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// quotaCache holds, per namespace, the last-listed ResourceQuota objects, so the admission plugin
+// doesn't hit the API server for a List on every admitted request. It's invalidated wholesale by
+// the resync notification the controller's ControllerFactory delivers for the ResourceQuota
+// GroupKind, so a quota create, update, or delete is picked up without the admission plugin
+// watching quotas on its own.
+type quotaCache struct {
+	kubeClient client.Interface
+
+	lock        sync.RWMutex
+	byNamespace map[string][]api.ResourceQuota
+}
+
+func newQuotaCache(kubeClient client.Interface) *quotaCache {
+	return &quotaCache{
+		kubeClient:  kubeClient,
+		byNamespace: map[string][]api.ResourceQuota{},
+	}
+}
+
+// invalidate drops every cached namespace's quota list, so the next list call re-fetches from the
+// API server.
+func (c *quotaCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.byNamespace = map[string][]api.ResourceQuota{}
+}
+
+// list returns namespace's ResourceQuota objects, fetching and caching them if they aren't
+// already cached.
+func (c *quotaCache) list(namespace string) ([]api.ResourceQuota, error) {
+	c.lock.RLock()
+	quotas, found := c.byNamespace[namespace]
+	c.lock.RUnlock()
+	if found {
+		return quotas, nil
+	}
+
+	list, err := c.kubeClient.ResourceQuotas(namespace).List(labels.Everything(), fields.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.byNamespace[namespace] = list.Items
+	c.lock.Unlock()
+	return list.Items, nil
+}
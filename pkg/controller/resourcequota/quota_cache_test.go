@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/util/sets"
+)
+
+// TestQuotaAdmissionInvalidatesCacheOnResourceQuotaChange verifies that the factory.NewController
+// call NewQuotaAdmission makes for GroupKind ResourceQuota results in cache.invalidate actually
+// being called once a namespace's ResourceQuota list changes, instead of the plugin serving a
+// stale cached list for the life of the process.
+func TestQuotaAdmissionInvalidatesCacheOnResourceQuotaChange(t *testing.T) {
+	const namespace = "test-ns"
+	quota := api.ResourceQuota{ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: "quota"}}
+
+	kubeClient := testclient.NewSimpleFake(&api.ResourceQuotaList{Items: []api.ResourceQuota{quota}})
+	factory := &monitoringControllerFactory{
+		kubeClient:        kubeClient,
+		resyncPeriod:      10 * time.Millisecond,
+		dynamicGroupKinds: sets.NewString(),
+	}
+
+	q, err := NewQuotaAdmission(kubeClient, NewDefaultUsageFuncRegistry(kubeClient), factory)
+	if err != nil {
+		t.Fatalf("unexpected error constructing quotaAdmission: %v", err)
+	}
+	cache := q.(*quotaAdmission).cache
+
+	if _, err := cache.list(namespace); err != nil {
+		t.Fatalf("unexpected error priming cache: %v", err)
+	}
+
+	updated := quota
+	updated.ResourceVersion = "2"
+	if _, err := kubeClient.ResourceQuotas(namespace).Update(&updated); err != nil {
+		t.Fatalf("unexpected error updating quota: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		cache.lock.RLock()
+		_, cached := cache.byNamespace[namespace]
+		cache.lock.RUnlock()
+		if !cached {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected cache to be invalidated after the ResourceQuota changed, but it never was")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
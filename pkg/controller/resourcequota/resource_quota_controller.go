@@ -0,0 +1,297 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/controller"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/quota"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+// UsageFunc computes the ResourceList a single object (a Pod, a Service, ...) consumes against
+// quota.
+type UsageFunc func(object runtime.Object) (api.ResourceList, error)
+
+// UsageFuncRegistry resolves the UsageFunc responsible for computing usage for a GroupKind.
+type UsageFuncRegistry interface {
+	UsageFunc(groupKind unversioned.GroupKind) (UsageFunc, bool)
+}
+
+// ControllerFactory starts whatever watch-and-cache machinery keeps a monitored GroupKind's
+// objects available locally, so a quota resync doesn't have to hit the API server once per
+// ResourceQuota. resyncFunc is invoked whenever a watched object changes, to recompute usage for
+// the ResourceQuotas that cover it.
+type ControllerFactory interface {
+	NewController(groupKind unversioned.GroupKind, resyncFunc func()) error
+}
+
+// ResourceQuotaControllerOptions holds the inputs required to construct a
+// ResourceQuotaController.
+type ResourceQuotaControllerOptions struct {
+	// KubeClient is used to list/watch monitored resources and to persist ResourceQuota status.
+	KubeClient client.Interface
+	// ResyncPeriod controls how often a full resync of every ResourceQuota is performed, as a
+	// backstop against a missed watch event.
+	ResyncPeriod controller.ResyncPeriodFunc
+	// UsageRegistry resolves the UsageFunc for each monitored GroupKind.
+	UsageRegistry UsageFuncRegistry
+	// ControllerFactory builds the per-GroupKind watch controllers.
+	ControllerFactory ControllerFactory
+	// GroupKindsToMonitor lists every GroupKind a ResourceQuota might cover usage for.
+	GroupKindsToMonitor []unversioned.GroupKind
+}
+
+// ResourceQuotaController is responsible for tracking quota usage status in the system.
+type ResourceQuotaController struct {
+	kubeClient          client.Interface
+	resyncPeriod        controller.ResyncPeriodFunc
+	usageRegistry       UsageFuncRegistry
+	controllerFactory   ControllerFactory
+	groupKindsToMonitor []unversioned.GroupKind
+}
+
+// NewResourceQuotaController creates a new ResourceQuotaController.
+func NewResourceQuotaController(options *ResourceQuotaControllerOptions) *ResourceQuotaController {
+	return &ResourceQuotaController{
+		kubeClient:          options.KubeClient,
+		resyncPeriod:        options.ResyncPeriod,
+		usageRegistry:       options.UsageRegistry,
+		controllerFactory:   options.ControllerFactory,
+		groupKindsToMonitor: options.GroupKindsToMonitor,
+	}
+}
+
+// Run starts watching every monitored GroupKind and blocks until stopCh is closed.
+func (rq *ResourceQuotaController) Run(stopCh <-chan struct{}) {
+	for _, groupKind := range rq.groupKindsToMonitor {
+		groupKind := groupKind
+		if err := rq.controllerFactory.NewController(groupKind, rq.resyncAll); err != nil {
+			glog.Errorf("failed to start resource quota monitor for %v: %v", groupKind, err)
+		}
+	}
+	wait.Until(rq.resyncAll, rq.resyncPeriod(), stopCh)
+}
+
+// resyncAll recomputes every ResourceQuota's Status.Used, in every namespace. It's the resyncFunc
+// passed to controllerFactory for each monitored GroupKind, so a change to any of them triggers a
+// full resync, and it's also run on a plain timer as a backstop against a missed change.
+func (rq *ResourceQuotaController) resyncAll() {
+	resourceQuotas, err := rq.kubeClient.ResourceQuotas(api.NamespaceAll).List(labels.Everything(), fields.Everything())
+	if err != nil {
+		glog.Errorf("failed to list resource quotas for resync: %v", err)
+		return
+	}
+	for _, resourceQuota := range resourceQuotas.Items {
+		if err := rq.syncResourceQuota(resourceQuota); err != nil {
+			glog.Errorf("failed to sync resource quota %s/%s: %v", resourceQuota.Namespace, resourceQuota.Name, err)
+		}
+	}
+}
+
+// syncResourceQuota runs a complete sync of current status for a single ResourceQuota, recomputing
+// Status.Used for every resource it declares a Status.Hard limit for, and persisting the result if
+// it's changed.
+func (rq *ResourceQuotaController) syncResourceQuota(resourceQuota api.ResourceQuota) (err error) {
+	if err := validateScopedResources(resourceQuota.Spec.Hard, resourceQuota.Spec.Scopes); err != nil {
+		return err
+	}
+
+	// quota is dirty if its status hard limits do not match the spec's hard limits
+	dirty := !resourceListEquals(resourceQuota.Spec.Hard, resourceQuota.Status.Hard)
+
+	usage := api.ResourceQuota{
+		ObjectMeta: resourceQuota.ObjectMeta,
+		Status: api.ResourceQuotaStatus{
+			Hard: api.ResourceList{},
+			Used: api.ResourceList{},
+		},
+	}
+	for k, v := range resourceQuota.Spec.Hard {
+		usage.Status.Hard[k] = v
+	}
+
+	hardResources := sets.NewString()
+	for k := range usage.Status.Hard {
+		hardResources.Insert(string(k))
+	}
+
+	var pods []*api.Pod
+	if hardResources.Has(string(api.ResourcePods)) || hardResources.Has(string(api.ResourceCPU)) || hardResources.Has(string(api.ResourceMemory)) {
+		podList, listErr := rq.kubeClient.Pods(resourceQuota.Namespace).List(labels.Everything(), fields.Everything())
+		if listErr != nil {
+			return listErr
+		}
+		pods = filterPodsByScopes(FilterQuotaPods(podList.Items), resourceQuota.Spec.Scopes)
+	}
+
+	for resourceName := range usage.Status.Hard {
+		var value resource.Quantity
+		switch resourceName {
+		case api.ResourcePods:
+			value = *resource.NewQuantity(int64(len(pods)), resource.DecimalSI)
+		case api.ResourceCPU:
+			value = PodsResourceRequirement(pods, api.ResourceCPU, true)
+		case api.ResourceMemory:
+			value = PodsResourceRequirement(pods, api.ResourceMemory, true)
+		default:
+			// resources without a known usage computation keep whatever was last observed
+			if observed, found := resourceQuota.Status.Used[resourceName]; found {
+				value = observed
+			}
+		}
+		usage.Status.Used[resourceName] = value
+	}
+
+	dirty = dirty || !resourceListEquals(usage.Status.Used, resourceQuota.Status.Used)
+	if !dirty {
+		return nil
+	}
+
+	_, err = rq.kubeClient.ResourceQuotas(usage.Namespace).Update(&usage)
+	return err
+}
+
+// resourceListEquals reports whether a and b contain the same set of resources with equal
+// quantities.
+func resourceListEquals(a, b api.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		other, found := b[k]
+		if !found || v.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterQuotaPods returns the subset of pods whose resource usage still counts against quota: a
+// pod that's Succeeded, or Failed without a policy that would restart it, has released its
+// resources and no longer contributes.
+func FilterQuotaPods(pods []api.Pod) []*api.Pod {
+	result := make([]*api.Pod, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == api.PodSucceeded {
+			continue
+		}
+		if pod.Status.Phase == api.PodFailed && pod.Spec.RestartPolicy != api.RestartPolicyAlways && pod.Spec.RestartPolicy != api.RestartPolicyOnFailure {
+			continue
+		}
+		result = append(result, pod)
+	}
+	return result
+}
+
+// containerResourceList returns a container's requested or limited resources, whichever
+// useRequests selects.
+func containerResourceList(container *api.Container, useRequests bool) api.ResourceList {
+	if useRequests {
+		return container.Resources.Requests
+	}
+	return container.Resources.Limits
+}
+
+// sumResourceLists adds every list in lists together, resource by resource.
+func sumResourceLists(lists []api.ResourceList) api.ResourceList {
+	result := api.ResourceList{}
+	for _, list := range lists {
+		for name, quantity := range list {
+			sum := result[name]
+			sum.Add(quantity)
+			result[name] = sum
+		}
+	}
+	return result
+}
+
+// maxResourceLists returns, resource by resource, the largest quantity found across lists.
+func maxResourceLists(lists []api.ResourceList) api.ResourceList {
+	result := api.ResourceList{}
+	for _, list := range lists {
+		result = quota.Max(result, list)
+	}
+	return result
+}
+
+// podEffectiveResourceList returns a pod's effective requests or limits: the larger of the sum
+// across pod.Spec.Containers (which all run concurrently) and the largest single value across
+// pod.Spec.InitContainers (which run sequentially, one at a time, before any regular container
+// starts). This is the pod's true footprint on the node, and what quota should charge against.
+func podEffectiveResourceList(pod *api.Pod, useRequests bool) api.ResourceList {
+	containerLists := make([]api.ResourceList, 0, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		containerLists = append(containerLists, containerResourceList(&pod.Spec.Containers[i], useRequests))
+	}
+	initContainerLists := make([]api.ResourceList, 0, len(pod.Spec.InitContainers))
+	for i := range pod.Spec.InitContainers {
+		initContainerLists = append(initContainerLists, containerResourceList(&pod.Spec.InitContainers[i], useRequests))
+	}
+	return quota.Max(sumResourceLists(containerLists), maxResourceLists(initContainerLists))
+}
+
+// requirementKind returns the human-readable name ("request" or "limit") useRequests selects, for
+// error messages.
+func requirementKind(useRequests bool) string {
+	if useRequests {
+		return "request"
+	}
+	return "limit"
+}
+
+// PodResourceRequirement returns pod's effective quantity of resourceName, i.e.
+// max(sum(containers), max(initContainers)). It returns an error if that quantity is zero, since a
+// pod with no declared (or explicitly zero) requirement cannot be charged against quota for
+// resourceName.
+func PodResourceRequirement(pod *api.Pod, resourceName api.ResourceName, useRequests bool) (resource.Quantity, error) {
+	value, found := podEffectiveResourceList(pod, useRequests)[resourceName]
+	if !found || value.Sign() == 0 {
+		return resource.Quantity{}, fmt.Errorf("pod %s does not have a %s for %s", pod.Name, requirementKind(useRequests), resourceName)
+	}
+	return value, nil
+}
+
+// PodHasResourceRequirement reports whether pod declares a non-zero requirement for resourceName.
+func PodHasResourceRequirement(pod *api.Pod, resourceName api.ResourceName, useRequests bool) bool {
+	_, err := PodResourceRequirement(pod, resourceName, useRequests)
+	return err == nil
+}
+
+// PodsResourceRequirement sums PodResourceRequirement across pods, treating a pod with no
+// requirement for resourceName as contributing zero.
+func PodsResourceRequirement(pods []*api.Pod, resourceName api.ResourceName, useRequests bool) resource.Quantity {
+	var sum resource.Quantity
+	for _, pod := range pods {
+		if value, err := PodResourceRequirement(pod, resourceName, useRequests); err == nil {
+			sum.Add(value)
+		}
+	}
+	return sum
+}
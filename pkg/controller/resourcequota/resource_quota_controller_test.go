@@ -82,6 +82,26 @@ func validPod(name string, numContainers int, resources api.ResourceRequirements
 	return pod
 }
 
+func validPodWithInit(name string, containerResources, initContainerResources []api.ResourceRequirements) *api.Pod {
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{Name: name, Namespace: "test"},
+		Spec:       api.PodSpec{},
+	}
+	for i, resources := range containerResources {
+		pod.Spec.Containers = append(pod.Spec.Containers, api.Container{
+			Image:     "foo:V" + strconv.Itoa(i),
+			Resources: resources,
+		})
+	}
+	for i, resources := range initContainerResources {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, api.Container{
+			Image:     "init:V" + strconv.Itoa(i),
+			Resources: resources,
+		})
+	}
+	return pod
+}
+
 func TestFilterQuotaPods(t *testing.T) {
 	pods := []api.Pod{
 		{
@@ -141,6 +161,56 @@ func TestFilterQuotaPods(t *testing.T) {
 	}
 }
 
+func TestPodMatchesScopes(t *testing.T) {
+	activeDeadline := int64(30)
+	bestEffortPod := validPod("best-effort", 1, getResourceRequirements(getResourceList("", ""), getResourceList("", "")))
+	burstablePod := validPod("burstable", 1, getResourceRequirements(getResourceList("100m", ""), getResourceList("", "")))
+	terminatingPod := validPod("terminating", 1, getResourceRequirements(getResourceList("", ""), getResourceList("", "")))
+	terminatingPod.Spec.ActiveDeadlineSeconds = &activeDeadline
+
+	testCases := []struct {
+		name     string
+		pod      *api.Pod
+		scopes   []api.ResourceQuotaScope
+		expected bool
+	}{
+		{"best-effort pod, BestEffort scope", bestEffortPod, []api.ResourceQuotaScope{api.ResourceQuotaScopeBestEffort}, true},
+		{"burstable pod, BestEffort scope", burstablePod, []api.ResourceQuotaScope{api.ResourceQuotaScopeBestEffort}, false},
+		{"best-effort pod, NotBestEffort scope", bestEffortPod, []api.ResourceQuotaScope{api.ResourceQuotaScopeNotBestEffort}, false},
+		{"burstable pod, NotBestEffort scope", burstablePod, []api.ResourceQuotaScope{api.ResourceQuotaScopeNotBestEffort}, true},
+		{"terminating pod, Terminating scope", terminatingPod, []api.ResourceQuotaScope{api.ResourceQuotaScopeTerminating}, true},
+		{"non-terminating pod, Terminating scope", burstablePod, []api.ResourceQuotaScope{api.ResourceQuotaScopeTerminating}, false},
+		{"terminating pod, NotTerminating scope", terminatingPod, []api.ResourceQuotaScope{api.ResourceQuotaScopeNotTerminating}, false},
+		{"non-terminating pod, NotTerminating scope", burstablePod, []api.ResourceQuotaScope{api.ResourceQuotaScopeNotTerminating}, true},
+		{"best-effort terminating pod, NotBestEffort and Terminating both required", terminatingPod, []api.ResourceQuotaScope{api.ResourceQuotaScopeNotBestEffort, api.ResourceQuotaScopeTerminating}, false},
+		{"no scopes always matches", burstablePod, []api.ResourceQuotaScope{}, true},
+	}
+	for _, item := range testCases {
+		if actual := PodMatchesScopes(item.pod, item.scopes); actual != item.expected {
+			t.Errorf("%s: expected %v, got %v", item.name, item.expected, actual)
+		}
+	}
+}
+
+func TestValidateScopedResources(t *testing.T) {
+	testCases := []struct {
+		name        string
+		hard        api.ResourceList
+		scopes      []api.ResourceQuotaScope
+		expectError bool
+	}{
+		{"no scopes, any resource", getResourceList("1", ""), nil, false},
+		{"pod resources with scope", api.ResourceList{api.ResourcePods: resource.MustParse("1")}, []api.ResourceQuotaScope{api.ResourceQuotaScopeBestEffort}, false},
+		{"services with scope is rejected", api.ResourceList{api.ResourceServices: resource.MustParse("1")}, []api.ResourceQuotaScope{api.ResourceQuotaScopeTerminating}, true},
+	}
+	for _, item := range testCases {
+		err := validateScopedResources(item.hard, item.scopes)
+		if item.expectError != (err != nil) {
+			t.Errorf("%s: expected error %v, got %v", item.name, item.expectError, err)
+		}
+	}
+}
+
 func TestSyncResourceQuota(t *testing.T) {
 	podList := api.PodList{
 		Items: []api.Pod{
@@ -390,6 +460,16 @@ func TestPodHasResourceRequirement(t *testing.T) {
 			useRequests:    false,
 			expectedResult: false,
 		},
+		{
+			// an init container request is enough on its own to satisfy the requirement, even
+			// though no regular container requests anything.
+			pod: validPodWithInit("init-only-request",
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("", ""), getResourceList("", ""))},
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("250m", ""), getResourceList("", ""))}),
+			resourceName:   api.ResourceCPU,
+			useRequests:    true,
+			expectedResult: true,
+		},
 	}
 	for _, item := range testCases {
 		if actual := PodHasResourceRequirement(item.pod, item.resourceName, item.useRequests); item.expectedResult != actual {
@@ -449,6 +529,53 @@ func TestPodResourceRequirement(t *testing.T) {
 			useRequests:    true,
 			expectedError:  true,
 		},
+		{
+			// init container requests more CPU than the regular containers sum to: since init
+			// containers run sequentially before regular containers start, the pod's effective
+			// request is the larger of the two.
+			pod: validPodWithInit("init-exceeds-containers",
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("100m", ""), getResourceList("", ""))},
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("500m", ""), getResourceList("", ""))}),
+			resourceName:   api.ResourceCPU,
+			expectedResult: "500m",
+			useRequests:    true,
+			expectedError:  false,
+		},
+		{
+			// regular containers sum to more than any single init container requests.
+			pod: validPodWithInit("containers-exceed-init",
+				[]api.ResourceRequirements{
+					getResourceRequirements(getResourceList("300m", ""), getResourceList("", "")),
+					getResourceRequirements(getResourceList("300m", ""), getResourceList("", "")),
+				},
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("100m", ""), getResourceList("", ""))}),
+			resourceName:   api.ResourceCPU,
+			expectedResult: "600m",
+			useRequests:    true,
+			expectedError:  false,
+		},
+		{
+			// an init container with no CPU request at all should not zero out the containers'
+			// sum -- the pod's effective request is still the containers' value.
+			pod: validPodWithInit("init-missing-request",
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("100m", ""), getResourceList("", ""))},
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("", ""), getResourceList("", ""))}),
+			resourceName:   api.ResourceCPU,
+			expectedResult: "100m",
+			useRequests:    true,
+			expectedError:  false,
+		},
+		{
+			// mixed request/limit mode: useRequests=false should compare limits, ignoring that
+			// the init container's request (not checked here) is larger.
+			pod: validPodWithInit("init-limit-mode",
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("", ""), getResourceList("", "1Gi"))},
+				[]api.ResourceRequirements{getResourceRequirements(getResourceList("", "2Gi"), getResourceList("", "1500Mi"))}),
+			resourceName:   api.ResourceMemory,
+			expectedResult: "1500Mi",
+			useRequests:    false,
+			expectedError:  false,
+		},
 	}
 	for _, item := range testCases {
 		actual, err := PodResourceRequirement(item.pod, item.resourceName, item.useRequests)
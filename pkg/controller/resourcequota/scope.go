@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/sets"
+)
+
+// podScopedResources are the only resources BestEffort/NotBestEffort/Terminating/NotTerminating
+// scopes make sense for, since they're the ones computed by walking the quota's filtered pod
+// list. A quota with a non-empty Scopes can't sensibly restrict a count like Services or
+// PersistentVolumeClaims, which have no notion of "terminating" or "best-effort".
+var podScopedResources = sets.NewString(
+	string(api.ResourceCPU),
+	string(api.ResourceMemory),
+	string(api.ResourcePods),
+)
+
+// validateScopedResources rejects a ResourceQuotaSpec that both narrows to one or more scopes and
+// declares a hard limit for a resource those scopes don't apply to.
+func validateScopedResources(hard api.ResourceList, scopes []api.ResourceQuotaScope) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	for resourceName := range hard {
+		if !podScopedResources.Has(string(resourceName)) {
+			return fmt.Errorf("resource %q cannot be restricted by scope(s) %v: only %v are computed per-pod", resourceName, scopes, podScopedResources.List())
+		}
+	}
+	return nil
+}
+
+// filterPodsByScopes returns the subset of pods matching every scope in scopes. An empty scopes
+// leaves pods untouched.
+func filterPodsByScopes(pods []*api.Pod, scopes []api.ResourceQuotaScope) []*api.Pod {
+	if len(scopes) == 0 {
+		return pods
+	}
+	result := make([]*api.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if PodMatchesScopes(pod, scopes) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// PodMatchesScopes reports whether pod satisfies every scope in scopes.
+func PodMatchesScopes(pod *api.Pod, scopes []api.ResourceQuotaScope) bool {
+	for _, scope := range scopes {
+		if !podMatchesScope(pod, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func podMatchesScope(pod *api.Pod, scope api.ResourceQuotaScope) bool {
+	switch scope {
+	case api.ResourceQuotaScopeBestEffort:
+		return isBestEffortPod(pod)
+	case api.ResourceQuotaScopeNotBestEffort:
+		return !isBestEffortPod(pod)
+	case api.ResourceQuotaScopeTerminating:
+		return pod.Spec.ActiveDeadlineSeconds != nil
+	case api.ResourceQuotaScopeNotTerminating:
+		return pod.Spec.ActiveDeadlineSeconds == nil
+	default:
+		return true
+	}
+}
+
+// isBestEffortPod reports whether pod declares no CPU or memory request or limit anywhere, i.e.
+// it would be assigned the BestEffort QoS class.
+func isBestEffortPod(pod *api.Pod) bool {
+	for _, resourceName := range []api.ResourceName{api.ResourceCPU, api.ResourceMemory} {
+		if PodHasResourceRequirement(pod, resourceName, true) || PodHasResourceRequirement(pod, resourceName, false) {
+			return false
+		}
+	}
+	return true
+}
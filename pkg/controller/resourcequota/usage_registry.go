@@ -0,0 +1,90 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// defaultUsageFuncRegistry resolves built-in UsageFuncs for the handful of GroupKinds the core API
+// server knows how to charge against quota. kubeClient is accepted for parity with a discovery- or
+// informer-backed registry, even though the built-in funcs only ever need the object handed to
+// them.
+type defaultUsageFuncRegistry struct {
+	kubeClient client.Interface
+	funcs      map[unversioned.GroupKind]UsageFunc
+}
+
+// NewDefaultUsageFuncRegistry returns a UsageFuncRegistry covering Pods, Services,
+// ReplicationControllers, and PersistentVolumeClaims.
+func NewDefaultUsageFuncRegistry(kubeClient client.Interface) UsageFuncRegistry {
+	r := &defaultUsageFuncRegistry{kubeClient: kubeClient}
+	r.funcs = map[unversioned.GroupKind]UsageFunc{
+		{Group: "", Kind: "Pod"}:                   r.podUsage,
+		{Group: "", Kind: "Service"}:                r.serviceUsage,
+		{Group: "", Kind: "ReplicationController"}: r.replicationControllerUsage,
+		{Group: "", Kind: "PersistentVolumeClaim"}:  r.persistentVolumeClaimUsage,
+	}
+	return r
+}
+
+func (r *defaultUsageFuncRegistry) UsageFunc(groupKind unversioned.GroupKind) (UsageFunc, bool) {
+	f, found := r.funcs[groupKind]
+	return f, found
+}
+
+// podUsage charges a pod's effective CPU and memory requests, plus one against api.ResourcePods,
+// so long as it's still in a phase FilterQuotaPods would count.
+func (r *defaultUsageFuncRegistry) podUsage(object runtime.Object) (api.ResourceList, error) {
+	pod, ok := object.(*api.Pod)
+	if !ok {
+		return nil, fmt.Errorf("expected *api.Pod, got %T", object)
+	}
+	if len(FilterQuotaPods([]api.Pod{*pod})) == 0 {
+		return api.ResourceList{}, nil
+	}
+	usage := podEffectiveResourceList(pod, true)
+	usage[api.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+	return usage, nil
+}
+
+func (r *defaultUsageFuncRegistry) serviceUsage(object runtime.Object) (api.ResourceList, error) {
+	if _, ok := object.(*api.Service); !ok {
+		return nil, fmt.Errorf("expected *api.Service, got %T", object)
+	}
+	return api.ResourceList{api.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI)}, nil
+}
+
+func (r *defaultUsageFuncRegistry) replicationControllerUsage(object runtime.Object) (api.ResourceList, error) {
+	if _, ok := object.(*api.ReplicationController); !ok {
+		return nil, fmt.Errorf("expected *api.ReplicationController, got %T", object)
+	}
+	return api.ResourceList{api.ResourceReplicationControllers: *resource.NewQuantity(1, resource.DecimalSI)}, nil
+}
+
+func (r *defaultUsageFuncRegistry) persistentVolumeClaimUsage(object runtime.Object) (api.ResourceList, error) {
+	if _, ok := object.(*api.PersistentVolumeClaim); !ok {
+		return nil, fmt.Errorf("expected *api.PersistentVolumeClaim, got %T", object)
+	}
+	return api.ResourceList{api.ResourcePersistentVolumeClaims: *resource.NewQuantity(1, resource.DecimalSI)}, nil
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// FailurePolicy controls what a webhook usage source does when its backend can't be reached (or
+// keeps erroring through RetryBackoff), analogous to ImagePolicyWebhook's defaultAllow.
+type FailurePolicy string
+
+const (
+	// FailurePolicyFail treats an unreachable backend as a hard sync error.
+	FailurePolicyFail FailurePolicy = "Fail"
+	// FailurePolicyIgnore treats an unreachable backend as "no usage contributed this sync",
+	// leaving Status.Used for that GroupKind's resources untouched.
+	FailurePolicyIgnore FailurePolicy = "Ignore"
+)
+
+// defaultWebhookRetryBackoff is used when a WebhookUsageSource doesn't set RetryBackoff.
+const defaultWebhookRetryBackoff = 500 * time.Millisecond
+
+// WebhookUsageConfig names one or more remote usage-evaluating services, read from a JSON or YAML
+// file passed to the controller binary. It follows the same shape as the ImagePolicyWebhook
+// admission config: a list of named backends, each with their own kubeconfig and policy.
+type WebhookUsageConfig struct {
+	Webhooks []WebhookUsageSource `json:"webhooks"`
+}
+
+// WebhookUsageSource is a single remote backend that computes quota usage for the GroupKinds it
+// declares.
+type WebhookUsageSource struct {
+	// Name identifies this source in logs and cache keys.
+	Name string `json:"name"`
+	// GroupKinds lists every GroupKind this backend can compute usage for.
+	GroupKinds []unversioned.GroupKind `json:"groupKinds"`
+	// KubeConfigFile points at a kubeconfig used to authenticate to the backend, the same way
+	// ImagePolicyWebhook authenticates to its backend.
+	KubeConfigFile string `json:"kubeConfigFile"`
+	// AllowTTL caches a successful usage computation for (namespace, GroupKind) for this long
+	// before it's recomputed.
+	AllowTTL time.Duration `json:"allowTTL"`
+	// DenyTTL caches a failed usage computation for (namespace, GroupKind) for this long before
+	// it's retried, so a persistently broken backend isn't hammered every sync.
+	DenyTTL time.Duration `json:"denyTTL"`
+	// RetryBackoff is how long to wait between retries of a single failed call, before DenyTTL
+	// takes over. Defaults to defaultWebhookRetryBackoff.
+	RetryBackoff time.Duration `json:"retryBackoff"`
+	// FailurePolicy governs behavior once retries are exhausted. Defaults to FailurePolicyFail.
+	FailurePolicy FailurePolicy `json:"failurePolicy"`
+}
+
+// LoadWebhookUsageConfig reads and validates a WebhookUsageConfig from a JSON or YAML file.
+func LoadWebhookUsageConfig(path string) (*WebhookUsageConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading webhook usage config %q: %v", path, err)
+	}
+	config, err := ParseWebhookUsageConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing webhook usage config %q: %v", path, err)
+	}
+	return config, nil
+}
+
+// ParseWebhookUsageConfig parses and validates a WebhookUsageConfig from JSON or YAML bytes. It's
+// shared by LoadWebhookUsageConfig and the
+// ResourceQuota admission plugin, which takes the same shape inline as its plugin config.
+func ParseWebhookUsageConfig(data []byte) (*WebhookUsageConfig, error) {
+	config := &WebhookUsageConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing webhook usage config: %v", err)
+	}
+	for i := range config.Webhooks {
+		source := &config.Webhooks[i]
+		if len(source.Name) == 0 {
+			return nil, fmt.Errorf("webhook usage source %d is missing a name", i)
+		}
+		if source.RetryBackoff == 0 {
+			source.RetryBackoff = defaultWebhookRetryBackoff
+		}
+		if source.FailurePolicy == "" {
+			source.FailurePolicy = FailurePolicyFail
+		}
+	}
+	return config, nil
+}
+
+// GroupKinds returns every GroupKind declared across every source in config, for merging into
+// ResourceQuotaControllerOptions.GroupKindsToMonitor.
+func (config *WebhookUsageConfig) GroupKinds() []unversioned.GroupKind {
+	var result []unversioned.GroupKind
+	for _, source := range config.Webhooks {
+		result = append(result, source.GroupKinds...)
+	}
+	return result
+}
+
+// cacheEntry holds the last computed usage (or error) for a (namespace, GroupKind) pair, so a
+// source isn't called more than once per AllowTTL/DenyTTL window.
+type cacheEntry struct {
+	expires time.Time
+	usage   api.ResourceList
+	err     error
+}
+
+// webhookUsageRegistry is a UsageFuncRegistry backed by one or more WebhookUsageSources.
+type webhookUsageRegistry struct {
+	sources map[unversioned.GroupKind]*webhookSource
+}
+
+// webhookSource is a single configured backend, ready to be called.
+type webhookSource struct {
+	config WebhookUsageSource
+	client *http.Client
+	url    string
+
+	lock  sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewWebhookUsageRegistry returns a UsageFuncRegistry where each configured source answers for the
+// GroupKinds it declares in config.
+func NewWebhookUsageRegistry(config *WebhookUsageConfig) (UsageFuncRegistry, error) {
+	registry := &webhookUsageRegistry{sources: map[unversioned.GroupKind]*webhookSource{}}
+	for _, sourceConfig := range config.Webhooks {
+		clientConfig, err := clientcmd.LoadFromFile(sourceConfig.KubeConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading kubeconfig for webhook usage source %q: %v", sourceConfig.Name, err)
+		}
+		restConfig, err := clientcmd.NewNonInteractiveClientConfig(*clientConfig, "", &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error building client config for webhook usage source %q: %v", sourceConfig.Name, err)
+		}
+		httpClient, err := restclient.HTTPClientFor(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error building HTTP client for webhook usage source %q: %v", sourceConfig.Name, err)
+		}
+		source := &webhookSource{
+			config: sourceConfig,
+			client: httpClient,
+			url:    restConfig.Host,
+			cache:  map[string]cacheEntry{},
+		}
+		for _, groupKind := range sourceConfig.GroupKinds {
+			registry.sources[groupKind] = source
+		}
+	}
+	return registry, nil
+}
+
+func (r *webhookUsageRegistry) UsageFunc(groupKind unversioned.GroupKind) (UsageFunc, bool) {
+	source, found := r.sources[groupKind]
+	if !found {
+		return nil, false
+	}
+	return func(object runtime.Object) (api.ResourceList, error) {
+		return source.usage(groupKind, object)
+	}, true
+}
+
+// webhookUsageRequest is POSTed to the backend; webhookUsageResponse is its reply.
+type webhookUsageRequest struct {
+	GroupKind unversioned.GroupKind `json:"groupKind"`
+	Object    runtime.RawExtension  `json:"object"`
+}
+
+type webhookUsageResponse struct {
+	Usage api.ResourceList `json:"usage"`
+}
+
+// usage computes object's usage, consulting and refreshing the per-(namespace,GroupKind) cache.
+func (s *webhookSource) usage(groupKind unversioned.GroupKind, object runtime.Object) (api.ResourceList, error) {
+	accessor, err := api.ObjectMetaFor(object)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := accessor.Namespace + "/" + groupKind.String()
+
+	s.lock.Lock()
+	if entry, found := s.cache[cacheKey]; found && entry.expires.After(time.Now()) {
+		s.lock.Unlock()
+		return entry.usage, entry.err
+	}
+	s.lock.Unlock()
+
+	usage, err := s.call(groupKind, object)
+
+	ttl := s.config.AllowTTL
+	if err != nil {
+		ttl = s.config.DenyTTL
+		if s.config.FailurePolicy == FailurePolicyIgnore {
+			usage, err = api.ResourceList{}, nil
+		}
+	}
+	s.lock.Lock()
+	s.cache[cacheKey] = cacheEntry{expires: time.Now().Add(ttl), usage: usage, err: err}
+	s.lock.Unlock()
+
+	return usage, err
+}
+
+// call POSTs object to the backend, retrying once after RetryBackoff on a transport or non-200
+// error before giving up.
+func (s *webhookSource) call(groupKind unversioned.GroupKind, object runtime.Object) (api.ResourceList, error) {
+	body, err := json.Marshal(&webhookUsageRequest{GroupKind: groupKind, Object: runtime.RawExtension{Object: object}})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding webhook usage request for %q: %v", s.config.Name, err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.config.RetryBackoff)
+		}
+		resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("webhook usage source %q returned status %d", s.config.Name, resp.StatusCode)
+			continue
+		}
+		result := &webhookUsageResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			lastErr = fmt.Errorf("error decoding webhook usage response from %q: %v", s.config.Name, err)
+			continue
+		}
+		return result.Usage, nil
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+)
+
+func TestParseWebhookUsageConfigDefaults(t *testing.T) {
+	config, err := ParseWebhookUsageConfig([]byte(`{"webhooks":[{"name":"widgets","groupKinds":[{"kind":"Widget"}],"kubeConfigFile":"/etc/widgets.conf"}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.Webhooks) != 1 {
+		t.Fatalf("expected 1 webhook usage source, got %d", len(config.Webhooks))
+	}
+	source := config.Webhooks[0]
+	if source.RetryBackoff != defaultWebhookRetryBackoff {
+		t.Errorf("expected RetryBackoff to default to %v, got %v", defaultWebhookRetryBackoff, source.RetryBackoff)
+	}
+	if source.FailurePolicy != FailurePolicyFail {
+		t.Errorf("expected FailurePolicy to default to %q, got %q", FailurePolicyFail, source.FailurePolicy)
+	}
+}
+
+func TestParseWebhookUsageConfigRequiresName(t *testing.T) {
+	if _, err := ParseWebhookUsageConfig([]byte(`{"webhooks":[{"groupKinds":[{"kind":"Widget"}]}]}`)); err == nil {
+		t.Errorf("expected an error for a webhook usage source missing a name")
+	}
+}
+
+// TestUsageRegistryFromConfig verifies the ResourceQuota admission plugin's config io.Reader is
+// optional (falling back to the built-in registry alone) and, when present, is composed with a
+// webhook-backed registry for the GroupKinds it declares, rather than being silently ignored.
+func TestUsageRegistryFromConfig(t *testing.T) {
+	kubeClient := testclient.NewSimpleFake()
+
+	table := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "no config falls back to the built-in registry",
+			config: "",
+		},
+		{
+			name:    "invalid kubeconfig surfaces an error instead of being silently dropped",
+			config:  `{"webhooks":[{"name":"widgets","groupKinds":[{"kind":"Widget"}],"kubeConfigFile":"/does/not/exist"}]}`,
+			wantErr: "error loading kubeconfig",
+		},
+	}
+
+	for _, item := range table {
+		var registry UsageFuncRegistry
+		var err error
+		if item.config == "" {
+			registry, err = usageRegistryFromConfig(kubeClient, nil)
+		} else {
+			registry, err = usageRegistryFromConfig(kubeClient, strings.NewReader(item.config))
+		}
+
+		if len(item.wantErr) != 0 {
+			if err == nil || !strings.Contains(err.Error(), item.wantErr) {
+				t.Errorf("%s: usageRegistryFromConfig() error = %v, want it to contain %q", item.name, err, item.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", item.name, err)
+		}
+		if _, found := registry.UsageFunc(unversioned.GroupKind{Kind: "Service"}); !found {
+			t.Errorf("%s: expected the built-in registry's Service UsageFunc to still resolve", item.name)
+		}
+	}
+}
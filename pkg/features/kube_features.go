@@ -0,0 +1,39 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+)
+
+const (
+	// NodeLease gates renewing a lightweight per-node Lease object as a heartbeat, in place of a
+	// full node status update, letting the kubelet prove liveness far more often than it posts
+	// status without multiplying apiserver/etcd write volume.
+	NodeLease utilfeature.Feature = "NodeLease"
+)
+
+// defaultKubernetesFeatureGates consists of all known Kubernetes-specific feature keys accepted by
+// utilfeature.DefaultFeatureGate. To add a new feature, define a key for it in this package and
+// add it here.
+var defaultKubernetesFeatureGates = map[utilfeature.Feature]utilfeature.FeatureSpec{
+	NodeLease: {Default: false, PreRelease: utilfeature.Alpha},
+}
+
+func init() {
+	utilfeature.DefaultFeatureGate.Add(defaultKubernetesFeatureGates)
+}
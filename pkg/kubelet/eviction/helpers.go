@@ -0,0 +1,243 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"sort"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/kubelet/qos"
+)
+
+// makeSignalObservations builds the signalObservations this manager's Thresholds are compared
+// against from summary, along with a statsFunc that looks up a single pod's stats by identity.
+func makeSignalObservations(summary *statsapi.Summary) (signalObservations, statsFunc) {
+	observations := signalObservations{}
+
+	if memory := summary.Node.Memory; memory != nil && memory.AvailableBytes != nil {
+		observations[SignalMemoryAvailable] = signalObservation{
+			available: *resource.NewQuantity(int64(*memory.AvailableBytes), resource.BinarySI),
+		}
+	}
+	if nodeFs := summary.Node.Fs; nodeFs != nil {
+		if nodeFs.AvailableBytes != nil {
+			observations[SignalNodeFsAvailable] = signalObservation{
+				available: *resource.NewQuantity(int64(*nodeFs.AvailableBytes), resource.BinarySI),
+			}
+		}
+		if nodeFs.InodesFree != nil {
+			observations[SignalNodeFsInodesFree] = signalObservation{
+				available: *resource.NewQuantity(int64(*nodeFs.InodesFree), resource.DecimalSI),
+			}
+		}
+	}
+	if runtime := summary.Node.Runtime; runtime != nil && runtime.ImageFs != nil {
+		if runtime.ImageFs.AvailableBytes != nil {
+			observations[SignalImageFsAvailable] = signalObservation{
+				available: *resource.NewQuantity(int64(*runtime.ImageFs.AvailableBytes), resource.BinarySI),
+			}
+		}
+		if runtime.ImageFs.InodesFree != nil {
+			observations[SignalImageFsInodesFree] = signalObservation{
+				available: *resource.NewQuantity(int64(*runtime.ImageFs.InodesFree), resource.DecimalSI),
+			}
+		}
+	}
+	if rlimit := summary.Node.Rlimit; rlimit != nil && rlimit.MaxPID != nil && rlimit.NumOfRunningProcesses != nil {
+		available := *rlimit.MaxPID - *rlimit.NumOfRunningProcesses
+		observations[SignalPIDAvailable] = signalObservation{
+			available: *resource.NewQuantity(available, resource.DecimalSI),
+			capacity:  *resource.NewQuantity(*rlimit.MaxPID, resource.DecimalSI),
+		}
+	}
+
+	statsByPodUID := map[string]statsapi.PodStats{}
+	for _, podStats := range summary.Pods {
+		statsByPodUID[podStats.PodRef.UID] = podStats
+	}
+	statsFn := func(pod *api.Pod) (statsapi.PodStats, bool) {
+		podStats, found := statsByPodUID[string(pod.UID)]
+		return podStats, found
+	}
+
+	return observations, statsFn
+}
+
+// thresholdsMet returns the subset of thresholds whose signal was observed in observations and
+// whose Operator reports the threshold as crossed.
+func thresholdsMet(thresholds []Threshold, observations signalObservations) []Threshold {
+	met := []Threshold{}
+	for _, threshold := range thresholds {
+		observed, found := observations[threshold.Signal]
+		if !found {
+			continue
+		}
+		if thresholdMet(threshold, observed) {
+			met = append(met, threshold)
+		}
+	}
+	return met
+}
+
+// thresholdMet reports whether observed crosses threshold according to threshold.Operator.
+func thresholdMet(threshold Threshold, observed signalObservation) bool {
+	switch threshold.Operator {
+	case OpLessThan:
+		return observed.available.Cmp(threshold.Value) < 0
+	default:
+		return false
+	}
+}
+
+// nodeConditions returns the set of NodeConditions implied by thresholds, e.g. a SignalMemoryAvailable
+// threshold implies api.NodeMemoryPressure.
+func nodeConditions(thresholds []Threshold) []api.NodeConditionType {
+	seen := map[api.NodeConditionType]bool{}
+	conditions := []api.NodeConditionType{}
+	for _, threshold := range thresholds {
+		condition, found := signalToNodeCondition[threshold.Signal]
+		if !found || seen[condition] {
+			continue
+		}
+		seen[condition] = true
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// podUsage returns how far over pod's request the observed usage of resourceName is, for the
+// container sum of pod's spec, given stats reported by statsFn. A pod with no stats, or whose
+// request for resourceName is unset, is treated as zero usage-above-request.
+func podUsage(pod *api.Pod, resourceName api.ResourceName, stats statsFunc) resource.Quantity {
+	podStats, found := stats(pod)
+	if !found {
+		return resource.Quantity{}
+	}
+
+	var used resource.Quantity
+	switch resourceName {
+	case memoryResourceName:
+		for _, container := range podStats.Containers {
+			if container.Memory != nil && container.Memory.WorkingSetBytes != nil {
+				used.Add(*resource.NewQuantity(int64(*container.Memory.WorkingSetBytes), resource.BinarySI))
+			}
+		}
+	case processResourceName:
+		for _, container := range podStats.Containers {
+			if container.ProcessCount != nil {
+				used.Add(*resource.NewQuantity(int64(*container.ProcessCount), resource.DecimalSI))
+			}
+		}
+	case inodeResourceName:
+		for _, container := range podStats.Containers {
+			if container.Rootfs != nil && container.Rootfs.InodesUsed != nil {
+				used.Add(*resource.NewQuantity(int64(*container.Rootfs.InodesUsed), resource.DecimalSI))
+			}
+		}
+	}
+
+	request := podRequest(pod, resourceName)
+	used.Sub(request)
+	if used.Sign() < 0 {
+		return resource.Quantity{}
+	}
+	return used
+}
+
+// the pseudo-resource names podUsage/podRequest rank pods by; only memoryResourceName corresponds
+// to a real container resource request, since crio's container API has no notion of a process or
+// inode "request" to compare usage against.
+const (
+	memoryResourceName  api.ResourceName = "memory"
+	processResourceName api.ResourceName = "__eviction_process_count"
+	inodeResourceName   api.ResourceName = "__eviction_inode_count"
+)
+
+// podRequest sums resourceName's request across pod's containers. Pseudo-resources that have no
+// concept of a request (process count, inode count) always return zero, so ranking by "usage above
+// request" for those signals degenerates to ranking by raw usage.
+func podRequest(pod *api.Pod, resourceName api.ResourceName) resource.Quantity {
+	var request resource.Quantity
+	if resourceName != memoryResourceName {
+		return request
+	}
+	for _, container := range pod.Spec.Containers {
+		if quantity, found := container.Resources.Requests[resourceName]; found {
+			request.Add(quantity)
+		}
+	}
+	return request
+}
+
+// priorityThenQoSThenUsageRank ranks pods for eviction under pressure on resourceName: lowest
+// Pod.Spec.Priority first, then BestEffort before Burstable before Guaranteed, then highest
+// usage-above-request for resourceName first.
+func priorityThenQoSThenUsageRank(resourceName api.ResourceName) rankFunc {
+	return func(pods []*api.Pod, stats statsFunc) {
+		sort.Sort(&podEvictionOrder{pods: pods, resourceName: resourceName, stats: stats})
+	}
+}
+
+// podEvictionOrder implements sort.Interface so the first element is the most preferred pod to
+// evict first.
+type podEvictionOrder struct {
+	pods         []*api.Pod
+	resourceName api.ResourceName
+	stats        statsFunc
+}
+
+func (o *podEvictionOrder) Len() int      { return len(o.pods) }
+func (o *podEvictionOrder) Swap(i, j int) { o.pods[i], o.pods[j] = o.pods[j], o.pods[i] }
+func (o *podEvictionOrder) Less(i, j int) bool {
+	p1, p2 := o.pods[i], o.pods[j]
+
+	priority1, priority2 := podPriority(p1), podPriority(p2)
+	if priority1 != priority2 {
+		return priority1 < priority2
+	}
+
+	qos1, qos2 := qosOrdinal(p1), qosOrdinal(p2)
+	if qos1 != qos2 {
+		return qos1 < qos2
+	}
+
+	usage1, usage2 := podUsage(p1, o.resourceName, o.stats), podUsage(p2, o.resourceName, o.stats)
+	return usage1.Cmp(usage2) > 0
+}
+
+// podPriority returns pod's scheduling priority, defaulting to 0 for a pod that predates priority
+// admission (Spec.Priority unset).
+func podPriority(pod *api.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// qosOrdinal ranks pod's QoS class from most to least preferred for eviction.
+func qosOrdinal(pod *api.Pod) int {
+	switch qos.GetPodQOS(pod) {
+	case api.PodQOSBestEffort:
+		return 0
+	case api.PodQOSBurstable:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// fakeSummaryProvider is a SummaryProvider whose result can be swapped out mid-test.
+type fakeSummaryProvider struct {
+	result *statsapi.Summary
+}
+
+func (f *fakeSummaryProvider) Get() (*statsapi.Summary, error) {
+	return f.result, nil
+}
+
+// newPod returns a pod named name with the given containers, whose UID also equals name so tests
+// can correlate it against the PodRef.UID of a statsapi.PodStats built by newPodMemoryStats.
+func newPod(name string, containers []api.Container) *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:  types.UID(name),
+			Name: name,
+		},
+		Spec: api.PodSpec{
+			Containers: containers,
+		},
+	}
+}
+
+// newContainer returns a container named name with the given resource requests and limits.
+func newContainer(name string, requests api.ResourceList, limits api.ResourceList) api.Container {
+	return api.Container{
+		Name: name,
+		Resources: api.ResourceRequirements{
+			Requests: requests,
+			Limits:   limits,
+		},
+	}
+}
+
+// newResourceList builds an api.ResourceList from cpu and memory quantity strings, omitting a
+// resource entirely when its string is empty.
+func newResourceList(cpu, memory string) api.ResourceList {
+	resourceList := api.ResourceList{}
+	if cpu != "" {
+		resourceList[api.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		resourceList[api.ResourceMemory] = resource.MustParse(memory)
+	}
+	return resourceList
+}
+
+// newPodMemoryStats returns PodStats for pod reporting workingSet as the sole container's memory
+// working set.
+func newPodMemoryStats(pod *api.Pod, workingSet resource.Quantity) statsapi.PodStats {
+	workingSetBytes := uint64(workingSet.Value())
+	containerStats := []statsapi.ContainerStats{}
+	for _, container := range pod.Spec.Containers {
+		containerStats = append(containerStats, statsapi.ContainerStats{
+			Name: container.Name,
+			Memory: &statsapi.MemoryStats{
+				WorkingSetBytes: &workingSetBytes,
+			},
+		})
+	}
+	return statsapi.PodStats{
+		PodRef: statsapi.PodReference{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+		},
+		Containers: containerStats,
+	}
+}
+
+// newPodProcessStats returns PodStats for pod reporting processCount as the sole container's
+// running process count, for tests driving SignalPIDAvailable.
+func newPodProcessStats(pod *api.Pod, processCount uint64) statsapi.PodStats {
+	containerStats := []statsapi.ContainerStats{}
+	for _, container := range pod.Spec.Containers {
+		containerStats = append(containerStats, statsapi.ContainerStats{
+			Name:         container.Name,
+			ProcessCount: &processCount,
+		})
+	}
+	return statsapi.PodStats{
+		PodRef: statsapi.PodReference{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+		},
+		Containers: containerStats,
+	}
+}
+
+// newPodInodeStats returns PodStats for pod reporting inodesUsed as the sole container's rootfs
+// inode usage, for tests driving SignalNodeFsInodesFree/SignalImageFsInodesFree.
+func newPodInodeStats(pod *api.Pod, inodesUsed uint64) statsapi.PodStats {
+	containerStats := []statsapi.ContainerStats{}
+	for _, container := range pod.Spec.Containers {
+		containerStats = append(containerStats, statsapi.ContainerStats{
+			Name: container.Name,
+			Rootfs: &statsapi.FsStats{
+				InodesUsed: &inodesUsed,
+			},
+		})
+	}
+	return statsapi.PodStats{
+		PodRef: statsapi.PodReference{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+		},
+		Containers: containerStats,
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/client/record"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// TestInodePressureKillsHighestInodeUsagePod verifies that under node filesystem inode pressure,
+// the manager evicts whichever pod is writing the most inodes on the affected filesystem.
+func TestInodePressureKillsHighestInodeUsagePod(t *testing.T) {
+	podLowInodes := newPod("low-inodes", []api.Container{
+		newContainer("low-inodes", newResourceList("", ""), newResourceList("", "")),
+	})
+	podHighInodes := newPod("high-inodes", []api.Container{
+		newContainer("high-inodes", newResourceList("", ""), newResourceList("", "")),
+	})
+
+	pods := []*api.Pod{podLowInodes, podHighInodes}
+	activePodsFunc := func() []*api.Pod {
+		return pods
+	}
+
+	podStats := map[*api.Pod]statsapi.PodStats{
+		podLowInodes:  newPodInodeStats(podLowInodes, 1000),
+		podHighInodes: newPodInodeStats(podHighInodes, 100000),
+	}
+	summaryMaker := func(inodesFree string) *statsapi.Summary {
+		val := resource.MustParse(inodesFree)
+		free := uint64(val.Value())
+		result := &statsapi.Summary{
+			Node: statsapi.NodeStats{
+				Fs: &statsapi.FsStats{
+					InodesFree: &free,
+				},
+			},
+			Pods: []statsapi.PodStats{},
+		}
+		for _, podStat := range podStats {
+			result.Pods = append(result.Pods, podStat)
+		}
+		return result
+	}
+
+	fakeClock := util.NewFakeClock(time.Now())
+	podKiller := &mockPodKiller{}
+	nodeRef := &api.ObjectReference{Kind: "Node", Name: "test", UID: types.UID("test"), Namespace: ""}
+
+	config := Config{
+		PressureTransitionPeriod: time.Minute * 5,
+		Thresholds: []Threshold{
+			{
+				Signal:   SignalNodeFsInodesFree,
+				Operator: OpLessThan,
+				Value:    resource.MustParse("1000000"),
+			},
+		},
+	}
+	summaryProvider := &fakeSummaryProvider{result: summaryMaker("5000000")}
+	manager := &managerImpl{
+		clock:           fakeClock,
+		killPodFunc:     podKiller.killPodNow,
+		config:          config,
+		recorder:        &record.FakeRecorder{},
+		summaryProvider: summaryProvider,
+		nodeRef:         nodeRef,
+		nodeConditionsLastObservedAt: nodeConditionsObservedAt{},
+		thresholdsFirstObservedAt:    thresholdsObservedAt{},
+	}
+
+	manager.synchronize(activePodsFunc)
+	if manager.IsUnderDiskPressure() {
+		t.Fatalf("Manager should not report disk pressure")
+	}
+
+	// induce inode pressure
+	summaryProvider.result = summaryMaker("500000")
+	manager.synchronize(activePodsFunc)
+
+	if !manager.IsUnderDiskPressure() {
+		t.Fatalf("Manager should report disk pressure")
+	}
+	if podKiller.pod != podHighInodes {
+		t.Errorf("Manager chose to kill pod: %v, but should have killed the pod using the most inodes: %v", podKiller.pod, podHighInodes)
+	}
+}
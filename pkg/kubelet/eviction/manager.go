@@ -0,0 +1,241 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+	"k8s.io/kubernetes/pkg/kubelet/qos"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+// the reason and message recorded against a pod evicted, or rejected on admission, by this manager.
+const (
+	reason          = "Evicted"
+	messageFmt      = "The node was low on resource: %v. "
+	admitMessageFmt = "The node was low on resource: %v."
+)
+
+// NewManager returns an unstarted Manager enforcing config against the stats summaryProvider
+// surfaces, killing pods via killPodFunc, and recording events against nodeRef with recorder.
+func NewManager(
+	summaryProvider SummaryProvider,
+	config Config,
+	killPodFunc KillPodFunc,
+	recorder record.EventRecorder,
+	nodeRef *api.ObjectReference,
+) Manager {
+	return &managerImpl{
+		clock:           util.RealClock{},
+		killPodFunc:     killPodFunc,
+		config:          config,
+		recorder:        recorder,
+		summaryProvider: summaryProvider,
+		nodeRef:         nodeRef,
+		nodeConditionsLastObservedAt: nodeConditionsObservedAt{},
+		thresholdsFirstObservedAt:    thresholdsObservedAt{},
+	}
+}
+
+// managerImpl implements Manager.
+type managerImpl struct {
+	sync.RWMutex
+
+	// clock is used to determine how long a threshold or condition has been observed, and is faked
+	// out in tests.
+	clock util.Clock
+	// killPodFunc terminates a pod chosen for eviction.
+	killPodFunc KillPodFunc
+	// config holds the thresholds this manager enforces.
+	config Config
+	// recorder records why a pod was evicted or a node condition changed.
+	recorder record.EventRecorder
+	// summaryProvider surfaces the node/pod stats this manager evaluates thresholds against.
+	summaryProvider SummaryProvider
+	// nodeRef references the node this manager protects, for event recording.
+	nodeRef *api.ObjectReference
+
+	// nodeConditions are the NodeConditions currently reported as true by this manager.
+	nodeConditions []api.NodeConditionType
+	// nodeConditionsLastObservedAt records when each NodeCondition was last observed to be true.
+	nodeConditionsLastObservedAt nodeConditionsObservedAt
+	// thresholdsFirstObservedAt records when each threshold's signal was first observed crossed,
+	// without an intervening synchronize where it was not crossed.
+	thresholdsFirstObservedAt thresholdsObservedAt
+}
+
+var _ Manager = &managerImpl{}
+
+// Start launches the manager's periodic synchronize loop.
+func (m *managerImpl) Start(podFunc ActivePodsFunc, monitoringInterval time.Duration) {
+	go wait.Until(func() { m.synchronize(podFunc) }, monitoringInterval, wait.NeverStop)
+}
+
+// IsUnderMemoryPressure returns true if the node is currently reporting api.NodeMemoryPressure.
+func (m *managerImpl) IsUnderMemoryPressure() bool {
+	return m.hasNodeCondition(api.NodeMemoryPressure)
+}
+
+// IsUnderDiskPressure returns true if the node is currently reporting api.NodeDiskPressure.
+func (m *managerImpl) IsUnderDiskPressure() bool {
+	return m.hasNodeCondition(api.NodeDiskPressure)
+}
+
+// IsUnderPIDPressure returns true if the node is currently reporting api.NodePIDPressure.
+func (m *managerImpl) IsUnderPIDPressure() bool {
+	return m.hasNodeCondition(api.NodePIDPressure)
+}
+
+func (m *managerImpl) hasNodeCondition(condition api.NodeConditionType) bool {
+	m.RLock()
+	defer m.RUnlock()
+	for _, nodeCondition := range m.nodeConditions {
+		if nodeCondition == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// Admit rejects new pods once any NodeCondition this manager reports is active, so a node under
+// pressure stops accepting work it can't reliably schedule.
+func (m *managerImpl) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	m.RLock()
+	nodeConditions := m.nodeConditions
+	m.RUnlock()
+
+	if len(nodeConditions) == 0 {
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+
+	// BestEffort pods are rejected outright under any pressure; every other pod is still admitted.
+	if qos.GetPodQOS(attrs.Pod) == api.PodQOSBestEffort {
+		return lifecycle.PodAdmitResult{
+			Admit:   false,
+			Reason:  reason,
+			Message: fmt.Sprintf(admitMessageFmt, nodeConditions),
+		}
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
+
+// synchronize is the manager's main loop body: it observes the node's signals, determines which
+// thresholds are met, updates NodeConditions, and if necessary, ranks and evicts a single pod.
+func (m *managerImpl) synchronize(podFunc ActivePodsFunc) {
+	summary, err := m.summaryProvider.Get()
+	if err != nil {
+		glog.Errorf("eviction manager: unable to get summary stats: %v", err)
+		return
+	}
+
+	observations, statsFn := makeSignalObservations(summary)
+	now := m.clock.Now()
+
+	thresholds := thresholdsMet(m.config.Thresholds, observations)
+	for _, threshold := range thresholds {
+		if _, found := m.thresholdsFirstObservedAt[threshold.Signal]; !found {
+			m.thresholdsFirstObservedAt[threshold.Signal] = now
+		}
+	}
+	for signal := range m.thresholdsFirstObservedAt {
+		if !signalIn(thresholds, signal) {
+			delete(m.thresholdsFirstObservedAt, signal)
+		}
+	}
+
+	metThresholds := []Threshold{}
+	for _, threshold := range thresholds {
+		if now.Sub(m.thresholdsFirstObservedAt[threshold.Signal]) >= threshold.GracePeriod {
+			metThresholds = append(metThresholds, threshold)
+		}
+	}
+
+	nowConditions := nodeConditions(metThresholds)
+
+	m.Lock()
+	for _, condition := range nowConditions {
+		m.nodeConditionsLastObservedAt[condition] = now
+	}
+	reportedConditions := []api.NodeConditionType{}
+	for condition, lastObservedAt := range m.nodeConditionsLastObservedAt {
+		if now.Sub(lastObservedAt) < m.config.PressureTransitionPeriod {
+			reportedConditions = append(reportedConditions, condition)
+		}
+	}
+	m.nodeConditions = reportedConditions
+	m.Unlock()
+
+	if len(metThresholds) == 0 {
+		return
+	}
+
+	// act on a single threshold per synchronize, the first met one in Config.Thresholds order, so
+	// one resource is reclaimed at a time rather than compounding several evictions in one pass.
+	threshold := metThresholds[0]
+	pods := podFunc()
+	rank := rankFuncFor(threshold.Signal)
+	rank(pods, statsFn)
+
+	if len(pods) == 0 {
+		return
+	}
+
+	podToEvict := pods[0]
+	status := api.PodStatus{
+		Phase:   api.PodFailed,
+		Reason:  reason,
+		Message: fmt.Sprintf(messageFmt, threshold.Signal),
+	}
+	gracePeriod := int64(0)
+	if err := m.killPodFunc(podToEvict, status, &gracePeriod); err != nil {
+		glog.Errorf("eviction manager: unable to evict pod %v/%v: %v", podToEvict.Namespace, podToEvict.Name, err)
+		return
+	}
+	if m.recorder != nil {
+		m.recorder.Eventf(m.nodeRef, api.EventTypeWarning, reason, messageFmt, threshold.Signal)
+	}
+}
+
+// rankFuncFor returns the rankFunc this manager uses to choose a victim for signal.
+func rankFuncFor(signal Signal) rankFunc {
+	switch signal {
+	case SignalPIDAvailable:
+		return priorityThenQoSThenUsageRank(processResourceName)
+	case SignalNodeFsInodesFree, SignalImageFsInodesFree:
+		return priorityThenQoSThenUsageRank(inodeResourceName)
+	default:
+		return priorityThenQoSThenUsageRank(memoryResourceName)
+	}
+}
+
+// signalIn reports whether signal is the Signal of one of thresholds.
+func signalIn(thresholds []Threshold, signal Signal) bool {
+	for _, threshold := range thresholds {
+		if threshold.Signal == signal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/client/record"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// TestPIDPressurePreservesHighPriorityPod verifies that under PID pressure a high-priority
+// best-effort pod is preserved while a low-priority burstable pod is evicted instead, since
+// priority is ranked ahead of QoS class when choosing a victim.
+func TestPIDPressurePreservesHighPriorityPod(t *testing.T) {
+	lowPriority := int32(0)
+	highPriority := int32(1000)
+
+	highPriorityBestEffort := newPod("high-priority-best-effort", []api.Container{
+		newContainer("high-priority-best-effort", newResourceList("", ""), newResourceList("", "")),
+	})
+	highPriorityBestEffort.Spec.Priority = &highPriority
+
+	lowPriorityBurstable := newPod("low-priority-burstable", []api.Container{
+		newContainer("low-priority-burstable", newResourceList("100m", "100Mi"), newResourceList("200m", "1Gi")),
+	})
+	lowPriorityBurstable.Spec.Priority = &lowPriority
+
+	pods := []*api.Pod{highPriorityBestEffort, lowPriorityBurstable}
+	activePodsFunc := func() []*api.Pod {
+		return pods
+	}
+
+	podStats := map[*api.Pod]statsapi.PodStats{
+		highPriorityBestEffort: newPodProcessStats(highPriorityBestEffort, 500),
+		lowPriorityBurstable:   newPodProcessStats(lowPriorityBurstable, 500),
+	}
+	summaryMaker := func(pidsAvailable int64, maxPID int64) *statsapi.Summary {
+		result := &statsapi.Summary{
+			Node: statsapi.NodeStats{
+				Rlimit: &statsapi.RlimitStats{
+					MaxPID:                &maxPID,
+					NumOfRunningProcesses: int64Ptr(maxPID - pidsAvailable),
+				},
+			},
+			Pods: []statsapi.PodStats{},
+		}
+		for _, podStat := range podStats {
+			result.Pods = append(result.Pods, podStat)
+		}
+		return result
+	}
+
+	fakeClock := util.NewFakeClock(time.Now())
+	podKiller := &mockPodKiller{}
+	nodeRef := &api.ObjectReference{Kind: "Node", Name: "test", UID: types.UID("test"), Namespace: ""}
+
+	config := Config{
+		PressureTransitionPeriod: time.Minute * 5,
+		Thresholds: []Threshold{
+			{
+				Signal:   SignalPIDAvailable,
+				Operator: OpLessThan,
+				Value:    resource.MustParse("1000"),
+			},
+		},
+	}
+	summaryProvider := &fakeSummaryProvider{result: summaryMaker(5000, 10000)}
+	manager := &managerImpl{
+		clock:           fakeClock,
+		killPodFunc:     podKiller.killPodNow,
+		config:          config,
+		recorder:        &record.FakeRecorder{},
+		summaryProvider: summaryProvider,
+		nodeRef:         nodeRef,
+		nodeConditionsLastObservedAt: nodeConditionsObservedAt{},
+		thresholdsFirstObservedAt:    thresholdsObservedAt{},
+	}
+
+	manager.synchronize(activePodsFunc)
+	if manager.IsUnderPIDPressure() {
+		t.Fatalf("Manager should not report PID pressure")
+	}
+
+	// induce PID pressure
+	summaryProvider.result = summaryMaker(500, 10000)
+	manager.synchronize(activePodsFunc)
+
+	if !manager.IsUnderPIDPressure() {
+		t.Fatalf("Manager should report PID pressure")
+	}
+	if podKiller.pod != lowPriorityBurstable {
+		t.Errorf("Manager chose to kill pod: %v, but should have preserved the high-priority pod and killed: %v", podKiller.pod, lowPriorityBurstable)
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// Signal identifies a node-level resource that can be observed for eviction purposes.
+type Signal string
+
+const (
+	// SignalMemoryAvailable is the amount of node memory still available, i.e. capacity - workingSet.
+	SignalMemoryAvailable Signal = "memory.available"
+	// SignalNodeFsAvailable is the available bytes on the filesystem backing the kubelet root dir.
+	SignalNodeFsAvailable Signal = "nodefs.available"
+	// SignalNodeFsInodesFree is the free inode count on the filesystem backing the kubelet root dir.
+	SignalNodeFsInodesFree Signal = "nodefs.inodesFree"
+	// SignalImageFsAvailable is the available bytes on the filesystem backing the container runtime's
+	// image and container storage.
+	SignalImageFsAvailable Signal = "imagefs.available"
+	// SignalImageFsInodesFree is the free inode count on the filesystem backing the container
+	// runtime's image and container storage.
+	SignalImageFsInodesFree Signal = "imagefs.inodesFree"
+	// SignalPIDAvailable is the number of process IDs still available to the node, derived from
+	// /proc/sys/kernel/pid_max minus the process IDs currently in use across all cgroups.
+	SignalPIDAvailable Signal = "pid.available"
+)
+
+// ThresholdOperator compares an observed signal's quantity against a Threshold's Value.
+type ThresholdOperator string
+
+// OpLessThan is satisfied when the observed quantity for a signal is less than the threshold Value.
+const OpLessThan ThresholdOperator = "LessThan"
+
+// Threshold defines a metric for when eviction should occur, and optionally a grace period and a
+// minimum amount of the resource to reclaim once the threshold has been met.
+type Threshold struct {
+	// Signal is the name of the signal this threshold applies to, e.g. SignalMemoryAvailable.
+	Signal Signal
+	// Operator compares the observed value of Signal against Value.
+	Operator ThresholdOperator
+	// Value is the threshold the observed signal is compared against.
+	Value resource.Quantity
+	// GracePeriod represents how long a threshold must be met before eviction is triggered. A zero
+	// GracePeriod triggers eviction as soon as the threshold is observed.
+	GracePeriod time.Duration
+	// MinReclaim is the minimum amount of the signal's resource to reclaim once eviction is
+	// triggered. The manager does not read it: it evicts a single pod per synchronize regardless.
+	MinReclaim *resource.Quantity
+}
+
+// Config holds the eviction manager's configuration, i.e. the set of Thresholds it enforces and how
+// long a pressure condition must be absent before the corresponding NodeCondition is cleared.
+type Config struct {
+	// PressureTransitionPeriod is the duration a pressure condition must no longer be observed before
+	// the manager stops reporting it, to avoid rapidly flapping node conditions.
+	PressureTransitionPeriod time.Duration
+	// MaxPodGracePeriodSeconds is a cap on the grace period used to terminate a pod chosen for
+	// eviction. The manager does not read it: it always terminates the pod with a zero grace period.
+	MaxPodGracePeriodSeconds int64
+	// Thresholds are the signals this manager evicts pods to relieve.
+	Thresholds []Threshold
+}
+
+// nodeConditionsObservedAt records the last time each NodeCondition was observed to be true, so the
+// manager can honor Config.PressureTransitionPeriod before clearing a condition.
+type nodeConditionsObservedAt map[api.NodeConditionType]time.Time
+
+// thresholdsObservedAt records the first time each Signal was observed to have crossed its threshold,
+// without an intervening observation where it was not crossed, so the manager can honor a
+// Threshold's GracePeriod before acting on it.
+type thresholdsObservedAt map[Signal]time.Time
+
+// signalObservations holds the most recently observed quantity for each signal the manager tracks.
+type signalObservations map[Signal]signalObservation
+
+// signalObservation is a signal's observed quantity, alongside the capacity it was measured against
+// where that's meaningful (e.g. node memory capacity, total node PIDs).
+type signalObservation struct {
+	available resource.Quantity
+	capacity  resource.Quantity
+}
+
+// SummaryProvider surfaces the node and pod resource usage summary the manager evaluates Thresholds
+// against. It is satisfied by the kubelet's stats summary provider in production, and by
+// fakeSummaryProvider in tests.
+type SummaryProvider interface {
+	// Get returns the latest node and pod usage summary.
+	Get() (*statsapi.Summary, error)
+}
+
+// Manager evaluates node resource pressure and evicts pods to relieve it.
+type Manager interface {
+	lifecycle.PodAdmitHandler
+
+	// Start runs the manager's synchronize loop, which periodically ranks and, if necessary, evicts
+	// pods until the node is no longer under resource pressure.
+	Start(podFunc ActivePodsFunc, monitoringInterval time.Duration)
+
+	// IsUnderMemoryPressure returns true if the node is currently experiencing memory pressure.
+	IsUnderMemoryPressure() bool
+	// IsUnderDiskPressure returns true if the node is currently experiencing disk or inode pressure.
+	IsUnderDiskPressure() bool
+	// IsUnderPIDPressure returns true if the node is currently experiencing process ID pressure.
+	IsUnderPIDPressure() bool
+}
+
+// ActivePodsFunc returns the pods currently active on the node, i.e. those the manager should
+// consider for ranking and eviction.
+type ActivePodsFunc func() []*api.Pod
+
+// KillPodFunc terminates pod, recording status as the pod's terminal status, optionally overriding
+// its normal termination grace period.
+type KillPodFunc func(pod *api.Pod, status api.PodStatus, gracePeriodOverride *int64) error
+
+// rankFunc orders pods from most to least preferred for eviction, given the signal that triggered
+// eviction and the usage stats the manager most recently observed.
+type rankFunc func(pods []*api.Pod, stats statsFunc)
+
+// statsFunc returns the usage stats the manager observed for pod, if any.
+type statsFunc func(pod *api.Pod) (statsapi.PodStats, bool)
+
+// signalToNodeCondition maps a Signal to the NodeCondition it drives.
+var signalToNodeCondition = map[Signal]api.NodeConditionType{
+	SignalMemoryAvailable:    api.NodeMemoryPressure,
+	SignalNodeFsAvailable:    api.NodeDiskPressure,
+	SignalNodeFsInodesFree:   api.NodeDiskPressure,
+	SignalImageFsAvailable:   api.NodeDiskPressure,
+	SignalImageFsInodesFree:  api.NodeDiskPressure,
+	SignalPIDAvailable:       api.NodePIDPressure,
+}
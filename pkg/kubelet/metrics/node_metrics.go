@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const kubeletSubsystem = "kubelet"
+
+var (
+	// NodeStatusUpdateDuration tracks how long each tryUpdateNodeStatus call takes, letting
+	// operators distinguish a kubelet stuck computing status from an apiserver that's slow to
+	// accept it.
+	NodeStatusUpdateDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_status_update_duration_seconds",
+			Help:      "Duration in seconds of node status update calls to the apiserver.",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+
+	// NodeStatusUpdateErrors counts failed node status update attempts by reason, so operators
+	// can tell a transient conflict apart from a dead apiserver connection.
+	NodeStatusUpdateErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_status_update_errors_total",
+			Help:      "Number of node status update errors, by reason (get, update, conflict, timeout).",
+		},
+		[]string{"reason"},
+	)
+
+	// NodeHeartbeatLastSuccessTimestamp is set to the time of the last successful node status
+	// update, letting operators alert on "this node hasn't reported in N minutes" directly from
+	// the metric rather than inferring it from the absence of updates.
+	NodeHeartbeatLastSuccessTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_heartbeat_last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful node status update.",
+		},
+	)
+
+	// NodeConditionTransitions counts every time a node condition's Status flips, by condition
+	// type and new status, so flapping conditions (e.g. rapid MemoryPressure True/False cycles)
+	// stand out.
+	NodeConditionTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_condition_transitions_total",
+			Help:      "Number of node condition transitions, by condition type and new status.",
+		},
+		[]string{"type", "status"},
+	)
+
+	// NodeRegistrationAttempts counts every attempt registerWithApiserver makes to register this
+	// node, regardless of outcome.
+	NodeRegistrationAttempts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_registration_attempts_total",
+			Help:      "Number of attempts made to register this node with the apiserver.",
+		},
+	)
+
+	// NodeRegistrationDuration tracks how long registerWithApiserver's retry loop takes end to
+	// end, from first attempt to a terminal success.
+	NodeRegistrationDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: kubeletSubsystem,
+			Name:      "node_registration_duration_seconds",
+			Help:      "Duration in seconds of registerWithApiserver's retry loop, from first attempt to success.",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+)
+
+var registerOnce sync.Once
+
+// Register registers all of this package's node heartbeat/reporting metrics with prometheus. It
+// is safe to call more than once; only the first call actually registers anything.
+func Register() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(NodeStatusUpdateDuration)
+		prometheus.MustRegister(NodeStatusUpdateErrors)
+		prometheus.MustRegister(NodeHeartbeatLastSuccessTimestamp)
+		prometheus.MustRegister(NodeConditionTransitions)
+		prometheus.MustRegister(NodeRegistrationAttempts)
+		prometheus.MustRegister(NodeRegistrationDuration)
+	})
+}
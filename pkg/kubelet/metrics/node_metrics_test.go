@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// TestRegisterIsIdempotent verifies Register can be called more than once (as it is every time
+// NewManager constructs a managerImpl) without panicking on a duplicate prometheus registration.
+func TestRegisterIsIdempotent(t *testing.T) {
+	Register()
+	Register()
+}
+
+// TestNodeHeartbeatLastSuccessTimestampReadsBack verifies the gauge tryUpdateNodeStatus and
+// renewNodeLease both set on success actually stores the value they observe.
+func TestNodeHeartbeatLastSuccessTimestampReadsBack(t *testing.T) {
+	NodeHeartbeatLastSuccessTimestamp.Set(1234)
+
+	var metric dto.Metric
+	if err := NodeHeartbeatLastSuccessTimestamp.Write(&metric); err != nil {
+		t.Fatalf("unexpected error reading back NodeHeartbeatLastSuccessTimestamp: %v", err)
+	}
+	if got := metric.GetGauge().GetValue(); got != 1234 {
+		t.Errorf("NodeHeartbeatLastSuccessTimestamp = %v, want 1234", got)
+	}
+}
+
+// TestNodeStatusUpdateErrorsLabelsByReason verifies a NodeStatusUpdateErrors increment under the
+// "conflict" reason label is read back from that label's counter, so operators can distinguish a
+// conflict from a dead apiserver connection in the metric alone.
+func TestNodeStatusUpdateErrorsLabelsByReason(t *testing.T) {
+	NodeStatusUpdateErrors.WithLabelValues("conflict").Inc()
+
+	var metric dto.Metric
+	if err := NodeStatusUpdateErrors.WithLabelValues("conflict").Write(&metric); err != nil {
+		t.Fatalf("unexpected error reading back NodeStatusUpdateErrors: %v", err)
+	}
+	if got := metric.GetCounter().GetValue(); got < 1 {
+		t.Errorf("NodeStatusUpdateErrors{reason=conflict} = %v, want >= 1", got)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubelet/eviction"
+	kubeletmetrics "k8s.io/kubernetes/pkg/kubelet/metrics"
+)
+
+// NodeConditionProvider is a pluggable source of truth for a single NodeCondition. Implementations
+// are reconciled into the node's status by reconcileCondition, which owns the search-or-create,
+// LastHeartbeatTime bookkeeping, and grace-gated transition logic so providers only need to answer
+// "what is this condition right now".
+type NodeConditionProvider interface {
+	// Type identifies the NodeCondition this provider reports.
+	Type() api.NodeConditionType
+	// Evaluate returns the condition's current status, reason, and message as of now.
+	Evaluate(now time.Time) (status api.ConditionStatus, reason, message string, err error)
+	// TransitionGrace is the minimum dwell time reconcileCondition requires before flipping
+	// Status, to avoid a condition flapping on a single noisy observation. A provider whose
+	// underlying source already applies its own hysteresis (e.g. the eviction manager's
+	// PressureTransitionPeriod) should return 0 here to avoid gating the same transition twice.
+	TransitionGrace() time.Duration
+}
+
+// reconcileCondition reconciles p's current evaluation into node's matching NodeCondition,
+// generalizing the search-or-create, grace-gated transition pattern setNodeOODCondition used to
+// hand-roll on its own. A condition newly created here starts Unknown so it always flips on the
+// first reconcile rather than waiting out TransitionGrace, matching the startup behavior the
+// original OOD handling relied on.
+func (m *managerImpl) reconcileCondition(node *api.Node, p NodeConditionProvider) {
+	status, reason, message, err := p.Evaluate(m.clock.Now())
+	if err != nil {
+		glog.Errorf("Unable to evaluate node condition %v: %v", p.Type(), err)
+		return
+	}
+
+	currentTime := unversioned.NewTime(m.clock.Now())
+	var condition *api.NodeCondition
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == p.Type() {
+			condition = &node.Status.Conditions[i]
+		}
+	}
+
+	isNew := condition == nil
+	if isNew {
+		// condition can't be appended to node.Status.Conditions yet: that would copy it into the
+		// slice, and the updates made to it below wouldn't be reflected there.
+		condition = &api.NodeCondition{Type: p.Type(), Status: api.ConditionUnknown}
+	}
+	condition.LastHeartbeatTime = currentTime
+
+	if condition.Status != status {
+		if condition.Status == api.ConditionUnknown || m.clock.Since(condition.LastTransitionTime.Time) >= p.TransitionGrace() {
+			condition.Status = status
+			condition.LastTransitionTime = currentTime
+			m.statusDirty = true
+			kubeletmetrics.NodeConditionTransitions.WithLabelValues(string(p.Type()), string(status)).Inc()
+			eventType := api.EventTypeNormal
+			if status == api.ConditionTrue {
+				eventType = api.EventTypeWarning
+			}
+			m.recordNodeStatusEvent(eventType, reason)
+		} else {
+			glog.V(4).Infof("Node condition %v wants to transition to %v, but last transition was less than %s ago", p.Type(), status, p.TransitionGrace())
+		}
+	}
+	condition.Reason = reason
+	condition.Message = message
+
+	if isNew {
+		node.Status.Conditions = append(node.Status.Conditions, *condition)
+	}
+}
+
+// oodConditionProvider adapts the legacy out-of-disk check to NodeConditionProvider so
+// setNodeOODCondition can be expressed in terms of reconcileCondition instead of its own copy of
+// the search-or-create/grace-gated transition logic.
+type oodConditionProvider struct {
+	m *managerImpl
+}
+
+func (p oodConditionProvider) Type() api.NodeConditionType { return api.NodeOutOfDisk }
+
+func (p oodConditionProvider) TransitionGrace() time.Duration {
+	return p.m.outOfDiskTransitionFrequency
+}
+
+func (p oodConditionProvider) Evaluate(now time.Time) (api.ConditionStatus, string, string, error) {
+	if p.m.isOutOfDisk() {
+		return api.ConditionTrue, "KubeletOutOfDisk", "out of disk space", nil
+	}
+	return api.ConditionFalse, "KubeletHasSufficientDisk", "kubelet has sufficient disk space available", nil
+}
+
+// pressureConditionProvider adapts one of eviction.Manager's IsUnderXPressure queries to
+// NodeConditionProvider. The eviction manager already derives these signals from cAdvisor stats
+// and applies its own Config.PressureTransitionPeriod hysteresis, so TransitionGrace is 0 here:
+// gating again in reconcileCondition would only delay the scheduler seeing a pressure taint behind
+// two separate grace periods instead of one.
+type pressureConditionProvider struct {
+	conditionType   api.NodeConditionType
+	isUnderPressure func() bool
+	trueReason      string
+	trueMessage     string
+	falseReason     string
+	falseMessage    string
+}
+
+func (p pressureConditionProvider) Type() api.NodeConditionType { return p.conditionType }
+
+func (p pressureConditionProvider) TransitionGrace() time.Duration { return 0 }
+
+func (p pressureConditionProvider) Evaluate(now time.Time) (api.ConditionStatus, string, string, error) {
+	if p.isUnderPressure() {
+		return api.ConditionTrue, p.trueReason, p.trueMessage, nil
+	}
+	return api.ConditionFalse, p.falseReason, p.falseMessage, nil
+}
+
+// defaultConditionProviders returns the built-in MemoryPressure, DiskPressure, and PIDPressure
+// NodeConditionProviders, each a thin wrapper around evictor's own pressure signals so the
+// scheduler can taint-evict off the same conditions the eviction manager already enforces.
+func defaultConditionProviders(evictor eviction.Manager) []NodeConditionProvider {
+	return []NodeConditionProvider{
+		pressureConditionProvider{
+			conditionType:   api.NodeMemoryPressure,
+			isUnderPressure: evictor.IsUnderMemoryPressure,
+			trueReason:      "KubeletHasInsufficientMemory",
+			trueMessage:     "kubelet has insufficient memory available",
+			falseReason:     "KubeletHasSufficientMemory",
+			falseMessage:    "kubelet has sufficient memory available",
+		},
+		pressureConditionProvider{
+			conditionType:   api.NodeDiskPressure,
+			isUnderPressure: evictor.IsUnderDiskPressure,
+			trueReason:      "KubeletHasDiskPressure",
+			trueMessage:     "kubelet has disk pressure",
+			falseReason:     "KubeletHasNoDiskPressure",
+			falseMessage:    "kubelet has no disk pressure",
+		},
+		pressureConditionProvider{
+			conditionType:   api.NodePIDPressure,
+			isUnderPressure: evictor.IsUnderPIDPressure,
+			trueReason:      "KubeletHasInsufficientPID",
+			trueMessage:     "kubelet has insufficient PID available",
+			falseReason:     "KubeletHasSufficientPID",
+			falseMessage:    "kubelet has sufficient PID available",
+		},
+	}
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	policyapi "k8s.io/kubernetes/pkg/apis/policy"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	"k8s.io/kubernetes/pkg/fields"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
+)
+
+// drainEvictionBackoffSteps bounds how many Eviction attempts Drain makes for a pod while they are
+// refused because of a PodDisruptionBudget or API server rate limiting, before falling back to a
+// DELETE.
+const drainEvictionBackoffSteps = 5
+
+// drainClient is the narrow subset of clientset.Interface Drain's stages call through, kept small
+// enough to fake by hand in tests the way fakeLeaseClient does for SetLeaseClient, since this tree
+// doesn't vendor the generated clientset's fake.
+type drainClient interface {
+	GetNode(name string) (*api.Node, error)
+	UpdateNode(node *api.Node) (*api.Node, error)
+	UpdateNodeStatus(node *api.Node) (*api.Node, error)
+	ListPodsOnNode(nodeName string) (*api.PodList, error)
+	Evict(eviction *policyapi.Eviction) error
+	DeletePod(namespace, name string, options *api.DeleteOptions) error
+	GetPod(namespace, name string) (*api.Pod, error)
+}
+
+// clientsetDrainClient adapts clientset.Interface to drainClient.
+type clientsetDrainClient struct {
+	clientset.Interface
+}
+
+func (c clientsetDrainClient) GetNode(name string) (*api.Node, error) {
+	return c.Core().Nodes().Get(name)
+}
+
+func (c clientsetDrainClient) UpdateNode(node *api.Node) (*api.Node, error) {
+	return c.Core().Nodes().Update(node)
+}
+
+func (c clientsetDrainClient) UpdateNodeStatus(node *api.Node) (*api.Node, error) {
+	return c.Core().Nodes().UpdateStatus(node)
+}
+
+func (c clientsetDrainClient) ListPodsOnNode(nodeName string) (*api.PodList, error) {
+	return c.Core().Pods(api.NamespaceAll).List(api.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName),
+	})
+}
+
+func (c clientsetDrainClient) Evict(eviction *policyapi.Eviction) error {
+	return c.Policy().Evictions(eviction.Namespace).Evict(eviction)
+}
+
+func (c clientsetDrainClient) DeletePod(namespace, name string, options *api.DeleteOptions) error {
+	return c.Core().Pods(namespace).Delete(name, options)
+}
+
+func (c clientsetDrainClient) GetPod(namespace, name string) (*api.Pod, error) {
+	return c.Core().Pods(namespace).Get(name)
+}
+
+// drain returns the drainClient Drain's stages call through, defaulting to an adapter over
+// kubeClient the first time it's needed.
+func (m *managerImpl) drain() drainClient {
+	if m.drainClient == nil {
+		m.drainClient = clientsetDrainClient{m.kubeClient}
+	}
+	return m.drainClient
+}
+
+// Drain cordons the node, evicts its pods in priority order, and once the drain completes (or
+// policy.Timeout's overall deadline expires) posts a final Ready=False,Reason=NodeShuttingDown
+// status. See the Manager interface doc for the callers that are expected to invoke this.
+func (m *managerImpl) Drain(drainPolicy DrainPolicy) error {
+	deadline := m.clock.Now().Add(drainPolicy.Timeout)
+
+	node, err := m.drain().GetNode(m.nodeName)
+	if err != nil {
+		return fmt.Errorf("error getting node %q: %v", m.nodeName, err)
+	}
+
+	for _, f := range m.defaultNodeDrainFuncs(deadline) {
+		if err := f(node, drainPolicy); err != nil {
+			return err
+		}
+	}
+	for _, f := range m.NodeDrainFuncs {
+		if err := f(node, drainPolicy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultNodeDrainFuncs is a factory that generates the built-in set of Drain stages: cordon,
+// evict, then post the final NotReady status.
+func (m *managerImpl) defaultNodeDrainFuncs(deadline time.Time) []NodeDrainFunc {
+	return []NodeDrainFunc{
+		m.cordonNode,
+		func(node *api.Node, policy DrainPolicy) error {
+			return m.evictPods(node, policy, deadline)
+		},
+		func(node *api.Node, policy DrainPolicy) error {
+			return m.postShuttingDownStatus(node)
+		},
+	}
+}
+
+// cordonNode marks node unschedulable so the scheduler stops placing new pods on it while it
+// drains.
+func (m *managerImpl) cordonNode(node *api.Node, policy DrainPolicy) error {
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	updated, err := m.drain().UpdateNode(node)
+	if err != nil {
+		return fmt.Errorf("error cordoning node %q: %v", m.nodeName, err)
+	}
+	*node = *updated
+	m.recordNodeStatusEvent(api.EventTypeNormal, kubecontainer.NodeNotSchedulable)
+	return nil
+}
+
+// evictPods evicts the node's pods in priority order, respecting PodDisruptionBudgets, skipping
+// mirror pods (which the static pod manager would simply recreate) and, unless overridden by
+// policy, DaemonSet-owned pods and pods using local emptyDir data.
+func (m *managerImpl) evictPods(node *api.Node, drainPolicy DrainPolicy, deadline time.Time) error {
+	podList, err := m.drain().ListPodsOnNode(m.nodeName)
+	if err != nil {
+		return fmt.Errorf("error listing pods on node %q: %v", m.nodeName, err)
+	}
+
+	candidates := []*api.Pod{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if _, isMirrorPod := pod.Annotations[kubetypes.ConfigMirrorAnnotationKey]; isMirrorPod {
+			continue
+		}
+		controllerRef := getControllerRef(pod)
+		if controllerRef != nil && controllerRef.Kind == "DaemonSet" {
+			if !drainPolicy.IgnoreDaemonSets {
+				return fmt.Errorf("pod %s/%s is managed by DaemonSet %s; set IgnoreDaemonSets to drain anyway", pod.Namespace, pod.Name, controllerRef.Name)
+			}
+			continue
+		}
+		if controllerRef == nil && !drainPolicy.Force {
+			return fmt.Errorf("pod %s/%s is not managed by a controller; set Force to drain it anyway", pod.Namespace, pod.Name)
+		}
+		if usesLocalData(pod) && !drainPolicy.DeleteLocalData {
+			return fmt.Errorf("pod %s/%s uses local emptyDir data; set DeleteLocalData to drain it anyway", pod.Namespace, pod.Name)
+		}
+		candidates = append(candidates, pod)
+	}
+
+	sort.Sort(&drainOrder{pods: candidates})
+
+	for i, pod := range candidates {
+		if m.clock.Now().After(deadline) {
+			glog.Warningf("node drain: deadline exceeded with %d pod(s) still pending eviction", len(candidates)-i)
+			return nil
+		}
+		if err := m.evictOrDeletePod(pod, drainPolicy, deadline); err != nil {
+			return err
+		}
+		m.recorder.Eventf(&api.ObjectReference{Kind: "Pod", Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID}, api.EventTypeNormal, "Evicted", "Pod evicted as part of draining node %s", m.nodeName)
+	}
+	return nil
+}
+
+// evictOrDeletePod evicts pod via the policy/v1beta1 Eviction subresource, retrying with
+// exponential backoff while the API server reports it's rate-limited or a PodDisruptionBudget
+// forbids it, falling back to a DELETE once those retries are exhausted, or at once on any other
+// Eviction error. It then polls until the pod is gone or policy.Timeout's deadline is reached.
+func (m *managerImpl) evictOrDeletePod(pod *api.Pod, drainPolicy DrainPolicy, deadline time.Time) error {
+	backoff := 1 * time.Second
+	evicted := false
+	for attempt := 0; attempt < drainEvictionBackoffSteps; attempt++ {
+		eviction := &policyapi.Eviction{
+			ObjectMeta: api.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		}
+		err := m.drain().Evict(eviction)
+		if err == nil {
+			evicted = true
+			break
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			break
+		}
+		glog.V(2).Infof("node drain: eviction of pod %s/%s rate-limited or blocked by a PodDisruptionBudget, retrying: %v", pod.Namespace, pod.Name, err)
+		<-m.clock.After(backoff)
+		backoff *= 2
+	}
+
+	if !evicted {
+		glog.Warningf("node drain: falling back to deleting pod %s/%s after eviction was refused", pod.Namespace, pod.Name)
+		var gracePeriod *int64
+		if drainPolicy.GracePeriodSeconds >= 0 {
+			gracePeriod = &drainPolicy.GracePeriodSeconds
+		}
+		err := m.drain().DeletePod(pod.Namespace, pod.Name, &api.DeleteOptions{GracePeriodSeconds: gracePeriod})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	for m.clock.Now().Before(deadline) {
+		_, err := m.drain().GetPod(pod.Namespace, pod.Name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		<-m.clock.After(1 * time.Second)
+	}
+	glog.Warningf("node drain: pod %s/%s did not disappear before the drain deadline", pod.Namespace, pod.Name)
+	return nil
+}
+
+// getControllerRef returns the OwnerReference that controls pod, or nil if pod has none.
+func getControllerRef(pod *api.Pod) *api.OwnerReference {
+	for i := range pod.OwnerReferences {
+		ref := &pod.OwnerReferences[i]
+		if ref.Controller != nil && *ref.Controller {
+			return ref
+		}
+	}
+	return nil
+}
+
+// usesLocalData reports whether pod mounts an emptyDir volume, whose contents are lost once the
+// pod is evicted from this node.
+func usesLocalData(pod *api.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// drainOrder sorts pods from least to most preferred to keep running, i.e. lowest priority first.
+type drainOrder struct {
+	pods []*api.Pod
+}
+
+func (o *drainOrder) Len() int      { return len(o.pods) }
+func (o *drainOrder) Swap(i, j int) { o.pods[i], o.pods[j] = o.pods[j], o.pods[i] }
+func (o *drainOrder) Less(i, j int) bool {
+	return podPriority(o.pods[i]) < podPriority(o.pods[j])
+}
+
+// podPriority returns pod's scheduling priority, defaulting to 0 for a pod that predates priority
+// admission (Spec.Priority unset).
+func podPriority(pod *api.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// postShuttingDownStatus posts the final node status once Drain completes, marking the node
+// NotReady so the scheduler and node controller stop counting on it.
+func (m *managerImpl) postShuttingDownStatus(node *api.Node) error {
+	currentTime := unversioned.NewTime(m.clock.Now())
+	shuttingDownCondition := api.NodeCondition{
+		Type:              api.NodeReady,
+		Status:            api.ConditionFalse,
+		Reason:            "NodeShuttingDown",
+		Message:           "kubelet is draining the node before shutting down",
+		LastHeartbeatTime: currentTime,
+	}
+
+	updated := false
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == api.NodeReady {
+			shuttingDownCondition.LastTransitionTime = currentTime
+			node.Status.Conditions[i] = shuttingDownCondition
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		shuttingDownCondition.LastTransitionTime = currentTime
+		node.Status.Conditions = append(node.Status.Conditions, shuttingDownCondition)
+	}
+
+	if _, err := m.drain().UpdateNodeStatus(node); err != nil {
+		return fmt.Errorf("error posting NodeShuttingDown status for node %q: %v", m.nodeName, err)
+	}
+	m.recordNodeStatusEvent(api.EventTypeNormal, kubecontainer.NodeNotReady)
+	return nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+	policyapi "k8s.io/kubernetes/pkg/apis/policy"
+	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/kubelet/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// fakeDrainClient is a minimal drainClient for driving evictPods/evictOrDeletePod in tests,
+// standing in for clientsetDrainClient since this tree doesn't vendor a fake generated clientset.
+type fakeDrainClient struct {
+	pods []api.Pod
+
+	evictErrs    []error
+	evictCalls   int
+	deleteCalled bool
+	getPodErr    error
+}
+
+func (f *fakeDrainClient) GetNode(name string) (*api.Node, error)             { return nil, nil }
+func (f *fakeDrainClient) UpdateNode(node *api.Node) (*api.Node, error)       { return node, nil }
+func (f *fakeDrainClient) UpdateNodeStatus(node *api.Node) (*api.Node, error) { return node, nil }
+
+func (f *fakeDrainClient) ListPodsOnNode(nodeName string) (*api.PodList, error) {
+	return &api.PodList{Items: f.pods}, nil
+}
+
+func (f *fakeDrainClient) Evict(eviction *policyapi.Eviction) error {
+	defer func() { f.evictCalls++ }()
+	if f.evictCalls < len(f.evictErrs) {
+		return f.evictErrs[f.evictCalls]
+	}
+	return nil
+}
+
+func (f *fakeDrainClient) DeletePod(namespace, name string, options *api.DeleteOptions) error {
+	f.deleteCalled = true
+	return nil
+}
+
+func (f *fakeDrainClient) GetPod(namespace, name string) (*api.Pod, error) {
+	if f.getPodErr != nil {
+		return nil, f.getPodErr
+	}
+	return nil, apierrors.NewNotFound(api.Resource("pods"), name)
+}
+
+func testManager(drainClient drainClient) *managerImpl {
+	return &managerImpl{
+		clock:       util.RealClock{},
+		nodeName:    "test-node",
+		recorder:    &record.FakeRecorder{},
+		drainClient: drainClient,
+	}
+}
+
+func daemonSetOwnedPod(namespace, name string) api.Pod {
+	isController := true
+	return api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			OwnerReferences: []api.OwnerReference{{Kind: "DaemonSet", Name: "ds", Controller: &isController}},
+		},
+	}
+}
+
+func replicaSetOwnedPod(namespace, name string) api.Pod {
+	isController := true
+	return api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			OwnerReferences: []api.OwnerReference{{Kind: "ReplicaSet", Name: "rs", Controller: &isController}},
+		},
+	}
+}
+
+// TestEvictPodsSkipRules verifies evictPods' up-front accept/reject decisions for mirror pods,
+// DaemonSet-owned pods, unmanaged pods, and pods using local emptyDir data, before it ever attempts
+// to evict anything.
+func TestEvictPodsSkipRules(t *testing.T) {
+	table := []struct {
+		name      string
+		pod       api.Pod
+		policy    DrainPolicy
+		expectErr bool
+	}{
+		{
+			name:      "mirror pod is silently skipped",
+			pod:       api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "mirror", Annotations: map[string]string{types.ConfigMirrorAnnotationKey: "true"}}},
+			policy:    DrainPolicy{},
+			expectErr: false,
+		},
+		{
+			name:      "DaemonSet-owned pod without IgnoreDaemonSets is denied",
+			pod:       daemonSetOwnedPod("ns", "ds-pod"),
+			policy:    DrainPolicy{},
+			expectErr: true,
+		},
+		{
+			name:      "DaemonSet-owned pod with IgnoreDaemonSets is skipped",
+			pod:       daemonSetOwnedPod("ns", "ds-pod"),
+			policy:    DrainPolicy{IgnoreDaemonSets: true},
+			expectErr: false,
+		},
+		{
+			name:      "unmanaged pod without Force is denied",
+			pod:       api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "bare-pod"}},
+			policy:    DrainPolicy{},
+			expectErr: true,
+		},
+		{
+			name:      "unmanaged pod with Force is evicted",
+			pod:       api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "bare-pod"}},
+			policy:    DrainPolicy{Force: true},
+			expectErr: false,
+		},
+		{
+			name: "emptyDir pod without DeleteLocalData is denied",
+			pod: api.Pod{
+				ObjectMeta: replicaSetOwnedPod("ns", "rs-pod").ObjectMeta,
+				Spec:       api.PodSpec{Volumes: []api.Volume{{Name: "scratch", VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{}}}}},
+			},
+			policy:    DrainPolicy{},
+			expectErr: true,
+		},
+		{
+			name: "emptyDir pod with DeleteLocalData is evicted",
+			pod: api.Pod{
+				ObjectMeta: replicaSetOwnedPod("ns", "rs-pod").ObjectMeta,
+				Spec:       api.PodSpec{Volumes: []api.Volume{{Name: "scratch", VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{}}}}},
+			},
+			policy:    DrainPolicy{DeleteLocalData: true},
+			expectErr: false,
+		},
+		{
+			name:      "pod owned by a non-DaemonSet controller is evicted without Force",
+			pod:       replicaSetOwnedPod("ns", "rs-pod"),
+			policy:    DrainPolicy{},
+			expectErr: false,
+		},
+	}
+
+	for _, item := range table {
+		fake := &fakeDrainClient{pods: []api.Pod{item.pod}}
+		m := testManager(fake)
+
+		err := m.evictPods(&api.Node{}, item.policy, time.Now().Add(time.Minute))
+		if item.expectErr != (err != nil) {
+			t.Errorf("%s: expected error %v, got %v", item.name, item.expectErr, err)
+		}
+	}
+}
+
+// TestEvictOrDeletePodEvictsOnSuccess verifies a successful Eviction call needs no DELETE fallback.
+func TestEvictOrDeletePodEvictsOnSuccess(t *testing.T) {
+	fake := &fakeDrainClient{}
+	m := testManager(fake)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "pod"}}
+
+	if err := m.evictOrDeletePod(pod, DrainPolicy{}, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.deleteCalled {
+		t.Errorf("expected no DELETE fallback after a successful eviction")
+	}
+	if fake.evictCalls != 1 {
+		t.Errorf("expected exactly one Evict call, got %d", fake.evictCalls)
+	}
+}
+
+// TestEvictOrDeletePodRetriesThenSucceeds verifies a rate-limited/PDB-blocked Eviction is retried
+// with backoff and, once it stops being refused, doesn't fall back to a DELETE.
+func TestEvictOrDeletePodRetriesThenSucceeds(t *testing.T) {
+	fake := &fakeDrainClient{
+		evictErrs: []error{apierrors.NewTooManyRequests("blocked by PodDisruptionBudget", 1)},
+	}
+	m := testManager(fake)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "pod"}}
+
+	if err := m.evictOrDeletePod(pod, DrainPolicy{}, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.deleteCalled {
+		t.Errorf("expected no DELETE fallback once the retried eviction succeeds")
+	}
+	if fake.evictCalls != 2 {
+		t.Errorf("expected a retry after the first rate-limited attempt, got %d Evict calls", fake.evictCalls)
+	}
+}
+
+// TestEvictOrDeletePodFallsBackToDelete verifies a non-retryable Eviction failure falls back to a
+// DELETE rather than retrying or giving up silently.
+func TestEvictOrDeletePodFallsBackToDelete(t *testing.T) {
+	fake := &fakeDrainClient{
+		evictErrs: []error{apierrors.NewForbidden(api.Resource("pods"), "pod", nil)},
+	}
+	m := testManager(fake)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "pod"}}
+
+	if err := m.evictOrDeletePod(pod, DrainPolicy{}, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fake.deleteCalled {
+		t.Errorf("expected evictOrDeletePod to fall back to DELETE after a non-retryable eviction error")
+	}
+	if fake.evictCalls != 1 {
+		t.Errorf("expected no retry for a non-retryable error, got %d Evict calls", fake.evictCalls)
+	}
+}
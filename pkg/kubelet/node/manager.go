@@ -24,17 +24,24 @@ import (
 	"github.com/golang/glog"
 	cadvisorapi "github.com/google/cadvisor/info/v1"
 
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/kubernetes/pkg/api"
 	apierrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	coordapi "k8s.io/kubernetes/pkg/apis/coordination"
 	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	coordinationclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/coordination/internalversion"
 	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/cloudprovider"
+	"k8s.io/kubernetes/pkg/features"
 	"k8s.io/kubernetes/pkg/kubelet/cadvisor"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/eviction"
+	kubeletmetrics "k8s.io/kubernetes/pkg/kubelet/metrics"
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/util/wait"
 	"k8s.io/kubernetes/pkg/version"
 )
@@ -42,11 +49,37 @@ import (
 const (
 	// nodeStatusUpdateRetry specifies how many times kubelet retries when posting node status failed.
 	nodeStatusUpdateRetry = 5
+	// nodeLeaseNamespace is where this node's Lease object, used as a lightweight heartbeat when
+	// the NodeLease feature is enabled, lives.
+	nodeLeaseNamespace = "kube-node-lease"
+	// defaultNodeLeaseDurationSeconds is how long a Lease renewal is considered valid for; it is
+	// also the basis for renewNodeLease's own renewal period (1/4 of this).
+	defaultNodeLeaseDurationSeconds = 40
+	// defaultNodeStatusReportFrequency bounds how long syncNodeStatusIfNeeded will go without a
+	// full tryUpdateNodeStatus even when nothing tracked by statusDirty changed, so the Lease
+	// heartbeat can carry routine proof-of-life without status reports going stale forever.
+	defaultNodeStatusReportFrequency = 5 * time.Minute
+	// initialNodeStatusUpdateBackoff is the delay before the second attempt in updateNodeStatus's
+	// retry loop; each subsequent attempt roughly doubles it, jittered, up to a cap.
+	initialNodeStatusUpdateBackoff = 100 * time.Millisecond
+	// defaultNodeStatusUpdateQPS and defaultNodeStatusUpdateBurst size the token bucket
+	// updateNodeStatus draws from before every attempt, so a thundering herd of kubelets hitting a
+	// flaky apiserver at once gets smoothed rather than amplified by the retry loop.
+	defaultNodeStatusUpdateQPS   = 1.0
+	defaultNodeStatusUpdateBurst = 2
 )
 
-// NewManager returns a Manager.
+// NewManager returns a Manager. Kubelet startup is expected to follow up with SetLeaseClient and
+// SetEvictionManager before calling Start, so the Lease heartbeat and pressure NodeConditionProviders
+// are actually wired in; neither can be supplied here since kubeClient and the eviction manager are
+// constructed afterward.
 func NewManager() Manager {
-	return managerImpl{}
+	kubeletmetrics.Register()
+	return &managerImpl{
+		nodeLeaseDurationSeconds:  defaultNodeLeaseDurationSeconds,
+		nodeStatusReportFrequency: defaultNodeStatusReportFrequency,
+		rateLimiter:               flowcontrol.NewTokenBucketRateLimiter(defaultNodeStatusUpdateQPS, defaultNodeStatusUpdateBurst),
+	}
 }
 
 // managerImpl imlements Manager
@@ -66,6 +99,9 @@ type managerImpl struct {
 	kubeClient clientset.Interface
 	// holds the set of functions that are registered to set node status.
 	NodeStatusFuncs
+	// holds the set of functions that are registered to run during Drain, in addition to the
+	// built-in cordon/evict/finalize stages.
+	NodeDrainFuncs
 	// used to record events about the node
 	recorder record.EventRecorder
 	// Set to true to have the node register itself with the apiserver.
@@ -86,17 +122,72 @@ type managerImpl struct {
 	// reservation specifies resources which are reserved for non-pod usage, including kubernetes and
 	// non-kubernetes system processes.
 	reservation kubetypes.Reservation
+	// leaseClient renews this node's Lease object as a lightweight heartbeat. Only used when the
+	// NodeLease feature is enabled.
+	leaseClient coordinationclient.LeaseInterface
+	// nodeLeaseDurationSeconds is how long a Lease renewal is valid for; renewNodeLease renews at
+	// 1/4 of this interval.
+	nodeLeaseDurationSeconds int32
+	// nodeStatusReportFrequency is the longest syncNodeStatusIfNeeded will go without calling
+	// tryUpdateNodeStatus when statusDirty is false.
+	nodeStatusReportFrequency time.Duration
+	// lastStatusReportTime records the last time syncNodeStatusIfNeeded actually posted a full
+	// node status, used to decide when nodeStatusReportFrequency has elapsed.
+	lastStatusReportTime time.Time
+	// statusDirty is set by a condition or info setter that changed something tryUpdateNodeStatus
+	// needs to report, and cleared once that report succeeds. Only read and written from the
+	// single syncNodeStatus goroutine started by Start, so it needs no lock of its own.
+	statusDirty bool
+	// reportedImages is the image list from the last setNodeStatusImages call, used to detect
+	// whether the node's image list actually changed.
+	reportedImages []api.ContainerImage
+	// evictionManager supplies the built-in MemoryPressure/DiskPressure/PIDPressure
+	// NodeConditionProviders, lazily registered into conditionProviders the first time
+	// defaultNodeStatusFuncs runs. Nil until the kubelet wires its eviction manager in.
+	evictionManager eviction.Manager
+	// conditionProviders holds every NodeConditionProvider setNodeConditionsFromProviders
+	// reconciles into the node on each status update, in addition to the built-in OOD/Ready
+	// conditions handled directly by setNodeOODCondition/setNodeReadyCondition.
+	conditionProviders []NodeConditionProvider
+	// rateLimiter is drawn from before every tryUpdateNodeStatus attempt in updateNodeStatus.
+	rateLimiter flowcontrol.RateLimiter
+	// diskPressureSince and pidPressureSince record when checkUnrecoverablePressure first observed
+	// each condition continuously true, or the zero Time while the condition is false. See
+	// pressure_drain.go.
+	diskPressureSince time.Time
+	pidPressureSince  time.Time
+	// drainedForPressure is set the first time checkUnrecoverablePressure triggers a Drain, so
+	// sustained pressure only ever drains the node once.
+	drainedForPressure bool
+	// drainClient is the narrow client Drain's stages call through. Nil until Drain's first use,
+	// at which point it defaults to an adapter over kubeClient; tests set it directly to a fake
+	// without needing to stand up the full generated clientset this tree doesn't vendor.
+	drainClient drainClient
 }
 
-// Start the node status manager.
-func (m *managerImpl) Start(nodeStatusUpdateFrequency time.Duration) {
-	// Start syncing node status immediately, this may set up things the runtime needs to run.
-	go wait.Until(m.syncNodeStatus, nodeStatusUpdateFrequency, wait.NeverStop)
+// Start the node status manager. When the NodeLease feature is enabled, syncNodeStatus runs every
+// heartbeatFrequency, and syncNodeStatusIfNeeded only posts a full tryUpdateNodeStatus when
+// statusDirty is set or statusReportFrequency has elapsed; a separate loop renews the Lease every
+// 1/4 of nodeLeaseDurationSeconds, independent of heartbeatFrequency. When
+// NodeLease is disabled there is no Lease to carry proof-of-life between status reports, so
+// syncNodeStatus is driven at statusReportFrequency instead of heartbeatFrequency: ticking a full
+// status round trip at the fast heartbeat cadence would multiply apiserver/etcd writes relative to
+// the pre-Lease baseline, the opposite of what decoupling the two frequencies was meant to achieve.
+func (m *managerImpl) Start(heartbeatFrequency, statusReportFrequency time.Duration) {
+	m.nodeStatusReportFrequency = statusReportFrequency
+	if utilfeature.DefaultFeatureGate.Enabled(features.NodeLease) {
+		go wait.Until(m.syncNodeStatus, heartbeatFrequency, wait.NeverStop)
+		renewInterval := time.Duration(m.nodeLeaseDurationSeconds/4) * time.Second
+		go wait.Until(m.renewNodeLease, renewInterval, wait.NeverStop)
+	} else {
+		go wait.Until(m.syncNodeStatus, statusReportFrequency, wait.NeverStop)
+	}
+	m.startPressureDrainMonitor()
 }
 
-// syncNodeStatus should be called periodically from a goroutine.
-// It synchronizes node status to master, registering the kubelet first if
-// necessary.
+// syncNodeStatus should be called periodically from a goroutine, at heartbeatFrequency when
+// NodeLease is enabled or statusReportFrequency otherwise (see Start). It synchronizes node status
+// to master, registering the kubelet first if necessary.
 func (m *managerImpl) syncNodeStatus() {
 	if m.kubeClient == nil {
 		return
@@ -105,21 +196,109 @@ func (m *managerImpl) syncNodeStatus() {
 		// This will exit immediately if it doesn't need to do anything.
 		m.registerWithApiserver()
 	}
+	m.syncNodeStatusIfNeeded()
+}
+
+// syncNodeStatusIfNeeded calls tryUpdateNodeStatus only when something tracked by statusDirty has
+// actually changed, or nodeStatusReportFrequency has elapsed since the last report, letting the
+// Lease renewal in renewNodeLease carry routine proof-of-life the rest of the time.
+func (m *managerImpl) syncNodeStatusIfNeeded() {
+	if !m.statusDirty && m.clock.Since(m.lastStatusReportTime) < m.nodeStatusReportFrequency {
+		return
+	}
 	if err := m.updateNodeStatus(); err != nil {
 		glog.Errorf("Unable to update node status: %v", err)
+		return
+	}
+	m.statusDirty = false
+	m.lastStatusReportTime = m.clock.Now()
+}
+
+// SetLeaseClient wires in the client renewNodeLease uses to renew this node's Lease object. Kubelet
+// startup is expected to call this once, scoped to nodeLeaseNamespace, before calling Start;
+// without it renewNodeLease silently no-ops even when the NodeLease feature is enabled.
+func (m *managerImpl) SetLeaseClient(leaseClient coordinationclient.LeaseInterface) {
+	m.leaseClient = leaseClient
+}
+
+// renewNodeLease renews this node's Lease object in nodeLeaseNamespace, creating it first if it
+// does not yet exist. The node controller accepts a fresh renewal as proof-of-life in place of a
+// full status update, so this can run far more often than tryUpdateNodeStatus without adding to
+// apiserver/etcd write volume the way a full status PUT would.
+//
+// Upstream carries Lease.Spec.RenewTime as a MicroTime for sub-second precision; this tree's
+// unversioned package only exposes second-resolution Time, so renewals here are second-granular.
+func (m *managerImpl) renewNodeLease() {
+	if m.leaseClient == nil {
+		return
+	}
+	lease, err := m.leaseClient.Get(m.nodeName)
+	if apierrors.IsNotFound(err) {
+		// newNodeLease already carries a fresh RenewTime, so creating the Lease is itself the renewal.
+		if _, err := m.leaseClient.Create(m.newNodeLease()); err != nil {
+			glog.Errorf("Unable to create node lease %q: %v", m.nodeName, err)
+			return
+		}
+		kubeletmetrics.NodeHeartbeatLastSuccessTimestamp.Set(float64(m.clock.Now().Unix()))
+		return
+	}
+	if err != nil {
+		glog.Errorf("Unable to renew node lease %q: %v", m.nodeName, err)
+		return
+	}
+	renewTime := unversioned.NewTime(m.clock.Now())
+	lease.Spec.RenewTime = &renewTime
+	if _, err := m.leaseClient.Update(lease); err != nil {
+		glog.Errorf("Unable to renew node lease %q: %v", m.nodeName, err)
+		return
+	}
+	kubeletmetrics.NodeHeartbeatLastSuccessTimestamp.Set(float64(m.clock.Now().Unix()))
+}
+
+// newNodeLease builds the Lease object renewNodeLease creates the first time it finds none for
+// this node.
+func (m *managerImpl) newNodeLease() *coordapi.Lease {
+	holderIdentity := m.nodeName
+	durationSeconds := m.nodeLeaseDurationSeconds
+	renewTime := unversioned.NewTime(m.clock.Now())
+	return &coordapi.Lease{
+		ObjectMeta: api.ObjectMeta{
+			Name:      m.nodeName,
+			Namespace: nodeLeaseNamespace,
+		},
+		Spec: coordapi.LeaseSpec{
+			HolderIdentity:       &holderIdentity,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &renewTime,
+		},
 	}
 }
 
 // updateNodeStatus updates node status to master with retries.
 func (m *managerImpl) updateNodeStatus() error {
+	maxBackoff := m.nodeStatusReportFrequency / 2
+	backoff := initialNodeStatusUpdateBackoff
+
+	var lastErr error
 	for i := 0; i < nodeStatusUpdateRetry; i++ {
+		if i > 0 {
+			<-m.clock.After(backoff)
+			backoff = wait.Jitter(backoff*2, 0.2)
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+		m.rateLimiter.Accept()
 		if err := m.tryUpdateNodeStatus(); err != nil {
+			lastErr = err
 			glog.Errorf("Error updating node status, will retry: %v", err)
-		} else {
-			return nil
+			continue
 		}
+		return nil
 	}
-	return fmt.Errorf("update node status exceeds retry count")
+	m.recorder.Eventf(m.nodeRef, api.EventTypeWarning, "NodeStatusUpdateFailed",
+		"Unable to update node status, possible causes: apiserver unreachable, rejected updates: %v", lastErr)
+	return fmt.Errorf("update node status exceeds retry count: %v", lastErr)
 }
 
 // registerWithApiserver registers the node with the cluster master. It is safe
@@ -129,6 +308,7 @@ func (m *managerImpl) registerWithApiserver() {
 	if m.registrationCompleted {
 		return
 	}
+	start := m.clock.Now()
 	step := 100 * time.Millisecond
 	for {
 		time.Sleep(step)
@@ -143,6 +323,7 @@ func (m *managerImpl) registerWithApiserver() {
 			continue
 		}
 		glog.V(2).Infof("Attempting to register node %s", node.Name)
+		kubeletmetrics.NodeRegistrationAttempts.Inc()
 		if _, err := m.kubeClient.Core().Nodes().Create(node); err != nil {
 			if !apierrors.IsAlreadyExists(err) {
 				glog.V(2).Infof("Unable to register %s with the apiserver: %v", node.Name, err)
@@ -160,6 +341,7 @@ func (m *managerImpl) registerWithApiserver() {
 			if currentNode.Spec.ExternalID == node.Spec.ExternalID {
 				glog.Infof("Node %s was previously registered", node.Name)
 				m.registrationCompleted = true
+				kubeletmetrics.NodeRegistrationDuration.Observe(m.clock.Since(start).Seconds())
 				return
 			}
 			glog.Errorf(
@@ -175,6 +357,7 @@ func (m *managerImpl) registerWithApiserver() {
 		}
 		glog.Infof("Successfully registered node %s", node.Name)
 		m.registrationCompleted = true
+		kubeletmetrics.NodeRegistrationDuration.Observe(m.clock.Since(start).Seconds())
 		return
 	}
 }
@@ -343,9 +526,33 @@ func (m *managerImpl) setNodeStatusImages(node *api.Node) {
 			})
 		}
 	}
+	if !imageListsEqual(m.reportedImages, imagesOnNode) {
+		m.statusDirty = true
+	}
+	m.reportedImages = imagesOnNode
 	node.Status.Images = imagesOnNode
 }
 
+// imageListsEqual compares two node image lists for the purposes of deciding whether the images
+// actually changed since the last report; ordering is assumed stable run to run since both come
+// from the same underlying imageManager.GetImageList() call.
+func imageListsEqual(a, b []api.ContainerImage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].SizeBytes != b[i].SizeBytes || len(a[i].Names) != len(b[i].Names) {
+			return false
+		}
+		for j := range a[i].Names {
+			if a[i].Names[j] != b[i].Names[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // Set status for the node.
 func (m *managerImpl) setNodeStatusInfo(node *api.Node) {
 	m.setNodeStatusMachineInfo(node)
@@ -405,6 +612,8 @@ func (m *managerImpl) setNodeReadyCondition(node *api.Node) {
 		node.Status.Conditions = append(node.Status.Conditions, newNodeReadyCondition)
 	}
 	if needToRecordEvent {
+		m.statusDirty = true
+		kubeletmetrics.NodeConditionTransitions.WithLabelValues(string(api.NodeReady), string(newNodeReadyCondition.Status)).Inc()
 		if newNodeReadyCondition.Status == api.ConditionTrue {
 			m.recordNodeStatusEvent(api.EventTypeNormal, kubecontainer.NodeReady)
 		} else {
@@ -415,66 +624,25 @@ func (m *managerImpl) setNodeReadyCondition(node *api.Node) {
 
 // Set OODcondition for the node.
 func (m *managerImpl) setNodeOODCondition(node *api.Node) {
-	currentTime := unversioned.NewTime(m.clock.Now())
-	var nodeOODCondition *api.NodeCondition
-
-	// Check if NodeOutOfDisk condition already exists and if it does, just pick it up for update.
-	for i := range node.Status.Conditions {
-		if node.Status.Conditions[i].Type == api.NodeOutOfDisk {
-			nodeOODCondition = &node.Status.Conditions[i]
-		}
-	}
+	m.reconcileCondition(node, oodConditionProvider{m: m})
+}
 
-	newOODCondition := false
-	// If the NodeOutOfDisk condition doesn't exist, create one.
-	if nodeOODCondition == nil {
-		nodeOODCondition = &api.NodeCondition{
-			Type:   api.NodeOutOfDisk,
-			Status: api.ConditionUnknown,
-		}
-		// nodeOODCondition cannot be appended to node.Status.Conditions here because it gets
-		// copied to the slice. So if we append nodeOODCondition to the slice here none of the
-		// updates we make to nodeOODCondition below are reflected in the slice.
-		newOODCondition = true
-	}
-
-	// Update the heartbeat time irrespective of all the conditions.
-	nodeOODCondition.LastHeartbeatTime = currentTime
-
-	// Note: The conditions below take care of the case when a new NodeOutOfDisk condition is
-	// created and as well as the case when the condition already exists. When a new condition
-	// is created its status is set to api.ConditionUnknown which matches either
-	// nodeOODCondition.Status != api.ConditionTrue or
-	// nodeOODCondition.Status != api.ConditionFalse in the conditions below depending on whether
-	// the kubelet is out of disk or not.
-	if m.isOutOfDisk() {
-		if nodeOODCondition.Status != api.ConditionTrue {
-			nodeOODCondition.Status = api.ConditionTrue
-			nodeOODCondition.Reason = "KubeletOutOfDisk"
-			nodeOODCondition.Message = "out of disk space"
-			nodeOODCondition.LastTransitionTime = currentTime
-			m.recordNodeStatusEvent(api.EventTypeNormal, "NodeOutOfDisk")
-		}
-	} else {
-		if nodeOODCondition.Status != api.ConditionFalse {
-			// Update the out of disk condition when the condition status is unknown even if we
-			// are within the outOfDiskTransitionFrequency duration. We do this to set the
-			// condition status correctly at kubelet startup.
-			if nodeOODCondition.Status == api.ConditionUnknown || m.clock.Since(nodeOODCondition.LastTransitionTime.Time) >= m.outOfDiskTransitionFrequency {
-				nodeOODCondition.Status = api.ConditionFalse
-				nodeOODCondition.Reason = "KubeletHasSufficientDisk"
-				nodeOODCondition.Message = "kubelet has sufficient disk space available"
-				nodeOODCondition.LastTransitionTime = currentTime
-				m.recordNodeStatusEvent(api.EventTypeNormal, "NodeHasSufficientDisk")
-			} else {
-				glog.Infof("Node condition status for OutOfDisk is false, but last transition time is less than %s", m.outOfDiskTransitionFrequency)
-			}
-		}
+// setNodeConditionsFromProviders reconciles every registered NodeConditionProvider into node,
+// covering the pluggable conditions (MemoryPressure, DiskPressure, PIDPressure, and whatever
+// external consumers register) that aren't wired in directly like setNodeOODCondition.
+func (m *managerImpl) setNodeConditionsFromProviders(node *api.Node) {
+	for _, p := range m.conditionProviders {
+		m.reconcileCondition(node, p)
 	}
+}
 
-	if newOODCondition {
-		node.Status.Conditions = append(node.Status.Conditions, *nodeOODCondition)
-	}
+// SetEvictionManager wires in the eviction.Manager defaultNodeStatusFuncs builds the built-in
+// MemoryPressure/DiskPressure/PIDPressure NodeConditionProviders from. Kubelet startup is expected
+// to call this once, after constructing its eviction manager, before the first node status sync;
+// without it those providers are never registered and the corresponding conditions never appear on
+// the node.
+func (m *managerImpl) SetEvictionManager(evictionManager eviction.Manager) {
+	m.evictionManager = evictionManager
 }
 
 // Maintains Node.Spec.Unschedulable value from previous run of tryUpdateNodeStatus()
@@ -514,9 +682,13 @@ func (m *managerImpl) defaultNodeStatusFuncs() []func(*api.Node) error {
 			return nil
 		}
 	}
+	if m.conditionProviders == nil && m.evictionManager != nil {
+		m.conditionProviders = defaultConditionProviders(m.evictionManager)
+	}
 	return []func(*api.Node) error{
 		m.setNodeAddress,
 		withoutError(m.setNodeStatusInfo),
+		withoutError(m.setNodeConditionsFromProviders),
 		withoutError(m.setNodeOODCondition),
 		withoutError(m.setNodeReadyCondition),
 		withoutError(m.recordNodeSchdulableEvent),
@@ -533,8 +705,14 @@ func SetNodeStatus(f func(*api.Node) error) Option {
 // tryUpdateNodeStatus tries to update node status to master. If ReconcileCBR0
 // is set, this function will also confirm that cbr0 is configured correctly.
 func (m *managerImpl) tryUpdateNodeStatus() error {
+	start := m.clock.Now()
+	defer func() {
+		kubeletmetrics.NodeStatusUpdateDuration.Observe(m.clock.Since(start).Seconds())
+	}()
+
 	node, err := m.kubeClient.Core().Nodes().Get(m.nodeName)
 	if err != nil {
+		kubeletmetrics.NodeStatusUpdateErrors.WithLabelValues(nodeStatusUpdateErrorReason(err, "get")).Inc()
 		return fmt.Errorf("error getting node %q: %v", m.nodeName, err)
 	}
 	if node == nil {
@@ -564,5 +742,24 @@ func (m *managerImpl) tryUpdateNodeStatus() error {
 	}
 	// Update the current status on the API server
 	_, err = m.kubeClient.Core().Nodes().UpdateStatus(node)
-	return err
+	if err != nil {
+		kubeletmetrics.NodeStatusUpdateErrors.WithLabelValues(nodeStatusUpdateErrorReason(err, "update")).Inc()
+		return err
+	}
+	kubeletmetrics.NodeHeartbeatLastSuccessTimestamp.Set(float64(m.clock.Now().Unix()))
+	return nil
+}
+
+// nodeStatusUpdateErrorReason classifies err into one of the node_status_update_errors_total
+// label values, falling back to fallback (the call site that failed) when err doesn't match a
+// more specific apiserver error type.
+func nodeStatusUpdateErrorReason(err error, fallback string) string {
+	switch {
+	case apierrors.IsConflict(err):
+		return "conflict"
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return "timeout"
+	default:
+		return fallback
+	}
 }
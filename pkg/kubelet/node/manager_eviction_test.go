@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/kubelet/eviction"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// fakePressureEvictionManager is a minimal eviction.Manager for verifying that
+// defaultConditionProviders' built-in NodeConditionProviders actually read from the manager passed
+// to SetEvictionManager, standing in for the real eviction manager this tree doesn't construct in
+// tests.
+type fakePressureEvictionManager struct {
+	memoryPressure bool
+	diskPressure   bool
+	pidPressure    bool
+}
+
+func (f *fakePressureEvictionManager) Start(podFunc eviction.ActivePodsFunc, monitoringInterval time.Duration) {
+}
+
+func (f *fakePressureEvictionManager) IsUnderMemoryPressure() bool { return f.memoryPressure }
+func (f *fakePressureEvictionManager) IsUnderDiskPressure() bool   { return f.diskPressure }
+func (f *fakePressureEvictionManager) IsUnderPIDPressure() bool    { return f.pidPressure }
+
+func (f *fakePressureEvictionManager) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	return lifecycle.PodAdmitResult{Admit: true}
+}
+
+// TestSetEvictionManagerWiresConditionProviders verifies that the eviction.Manager passed to
+// SetEvictionManager is the one defaultNodeStatusFuncs builds the Memory/Disk/PIDPressure
+// NodeConditionProviders from, and that those providers report the manager's live pressure state.
+func TestSetEvictionManagerWiresConditionProviders(t *testing.T) {
+	evictor := &fakePressureEvictionManager{memoryPressure: true}
+	m := &managerImpl{}
+
+	m.SetEvictionManager(evictor)
+	m.defaultNodeStatusFuncs()
+
+	if len(m.conditionProviders) != 3 {
+		t.Fatalf("expected 3 condition providers (Memory/Disk/PIDPressure), got %d", len(m.conditionProviders))
+	}
+
+	var sawMemoryPressure bool
+	for _, p := range m.conditionProviders {
+		if p.Type() != api.NodeMemoryPressure {
+			continue
+		}
+		sawMemoryPressure = true
+		status, _, _, err := p.Evaluate(time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error evaluating MemoryPressure: %v", err)
+		}
+		if status != api.ConditionTrue {
+			t.Errorf("expected MemoryPressure to reflect the wired eviction manager's pressure state (true), got %v", status)
+		}
+	}
+	if !sawMemoryPressure {
+		t.Fatalf("expected a MemoryPressure condition provider among %#v", m.conditionProviders)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+	coordapi "k8s.io/kubernetes/pkg/apis/coordination"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// fakeLeaseClient is a minimal coordinationclient.LeaseInterface for verifying renewNodeLease,
+// standing in for the real generated fake since this tree doesn't vendor clientset_generated.
+type fakeLeaseClient struct {
+	lease       *coordapi.Lease
+	getCalls    int
+	createCalls int
+	updateCalls int
+}
+
+func (f *fakeLeaseClient) Get(name string) (*coordapi.Lease, error) {
+	f.getCalls++
+	if f.lease == nil {
+		return nil, apierrors.NewNotFound(coordapi.Resource("leases"), name)
+	}
+	return f.lease, nil
+}
+
+func (f *fakeLeaseClient) Create(lease *coordapi.Lease) (*coordapi.Lease, error) {
+	f.createCalls++
+	f.lease = lease
+	return f.lease, nil
+}
+
+func (f *fakeLeaseClient) Update(lease *coordapi.Lease) (*coordapi.Lease, error) {
+	f.updateCalls++
+	f.lease = lease
+	return f.lease, nil
+}
+
+func (f *fakeLeaseClient) Delete(name string, options *api.DeleteOptions) error { return nil }
+
+func (f *fakeLeaseClient) DeleteCollection(options *api.DeleteOptions, listOptions api.ListOptions) error {
+	return nil
+}
+
+func (f *fakeLeaseClient) List(opts api.ListOptions) (*coordapi.LeaseList, error) {
+	return &coordapi.LeaseList{}, nil
+}
+
+func (f *fakeLeaseClient) Watch(opts api.ListOptions) (watch.Interface, error) { return nil, nil }
+
+func (f *fakeLeaseClient) Patch(name string, pt api.PatchType, data []byte, subresources ...string) (*coordapi.Lease, error) {
+	return f.lease, nil
+}
+
+// TestSetLeaseClientWiresRenewNodeLease verifies that the client passed to SetLeaseClient is the
+// one renewNodeLease actually renews against, rather than renewNodeLease silently no-oping because
+// leaseClient was never assigned.
+func TestSetLeaseClientWiresRenewNodeLease(t *testing.T) {
+	fake := &fakeLeaseClient{}
+	m := &managerImpl{
+		clock:                    util.RealClock{},
+		nodeName:                 "test-node",
+		nodeLeaseDurationSeconds: defaultNodeLeaseDurationSeconds,
+	}
+
+	m.SetLeaseClient(fake)
+	m.renewNodeLease()
+
+	if fake.createCalls != 1 {
+		t.Errorf("expected renewNodeLease to create the Lease the first time it found none, got %d creates", fake.createCalls)
+	}
+	if fake.lease == nil || fake.lease.Name != "test-node" {
+		t.Fatalf("expected a Lease named %q, got %#v", "test-node", fake.lease)
+	}
+
+	m.renewNodeLease()
+	if fake.updateCalls != 1 {
+		t.Errorf("expected the second renewNodeLease call to update the existing Lease, got %d updates", fake.updateCalls)
+	}
+}
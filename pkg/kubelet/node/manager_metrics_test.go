@@ -0,0 +1,45 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+)
+
+// TestNodeStatusUpdateErrorReason verifies the node_status_update_errors_total reason label
+// distinguishes a conflict or timeout from the generic get/update call site that failed.
+func TestNodeStatusUpdateErrorReason(t *testing.T) {
+	table := []struct {
+		name     string
+		err      error
+		fallback string
+		want     string
+	}{
+		{"conflict", apierrors.NewConflict(api.Resource("nodes"), "node1", nil), "update", "conflict"},
+		{"timeout", apierrors.NewTimeoutError("update node1", 0), "update", "timeout"},
+		{"other falls back to call site", apierrors.NewNotFound(api.Resource("nodes"), "node1"), "get", "get"},
+	}
+
+	for _, item := range table {
+		if got := nodeStatusUpdateErrorReason(item.err, item.fallback); got != item.want {
+			t.Errorf("%s: nodeStatusUpdateErrorReason() = %q, want %q", item.name, got, item.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// TestSyncNodeStatusIfNeededSkipsWhenCleanAndFresh verifies syncNodeStatusIfNeeded returns without
+// touching the apiserver (and so without needing a kubeClient at all) when nothing is dirty and
+// nodeStatusReportFrequency hasn't elapsed since the last report, so the Lease heartbeat is left to
+// carry proof-of-life the rest of the time.
+func TestSyncNodeStatusIfNeededSkipsWhenCleanAndFresh(t *testing.T) {
+	fakeClock := util.NewFakeClock(time.Now())
+	lastReport := fakeClock.Now()
+	m := &managerImpl{
+		clock:                     fakeClock,
+		statusDirty:               false,
+		lastStatusReportTime:      lastReport,
+		nodeStatusReportFrequency: 5 * time.Minute,
+	}
+
+	fakeClock.Step(1 * time.Minute)
+	m.syncNodeStatusIfNeeded()
+
+	if m.lastStatusReportTime != lastReport {
+		t.Errorf("expected lastStatusReportTime to be untouched when clean and fresh, got %v, want %v", m.lastStatusReportTime, lastReport)
+	}
+}
+
+// TestNewManagerConfiguresRateLimiter verifies NewManager wires a rateLimiter, so updateNodeStatus's
+// retry loop draws from a real token bucket instead of a nil one that would panic on first use.
+func TestNewManagerConfiguresRateLimiter(t *testing.T) {
+	m, ok := NewManager().(*managerImpl)
+	if !ok {
+		t.Fatalf("expected NewManager() to return a *managerImpl")
+	}
+	if m.rateLimiter == nil {
+		t.Fatalf("expected NewManager() to configure a rateLimiter")
+	}
+	m.rateLimiter.Accept()
+}
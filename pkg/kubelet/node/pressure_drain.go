@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+const (
+	// unrecoverablePressureCheckInterval is how often checkUnrecoverablePressure polls the eviction
+	// manager's disk/PID pressure signals.
+	unrecoverablePressureCheckInterval = 1 * time.Minute
+	// unrecoverablePressureDrainThreshold is how long disk or PID pressure must hold continuously
+	// before it's treated as unrecoverable by eviction alone, and the node is drained instead.
+	unrecoverablePressureDrainThreshold = 15 * time.Minute
+	// unrecoverablePressureDrainTimeout bounds the Drain triggered by sustained pressure; it stays
+	// conservative (no Force, no DeleteLocalData) since an automatic drain shouldn't discard data a
+	// human evicting the node by hand wouldn't have discarded either.
+	unrecoverablePressureDrainTimeout = 5 * time.Minute
+)
+
+// startPressureDrainMonitor starts the loop that calls Drain once disk or PID pressure has held
+// continuously for longer than unrecoverablePressureDrainThreshold, on the theory that pressure the
+// eviction manager can't resolve by evicting pods usually means the node itself, not just its pods,
+// needs to come out of rotation. It is a no-op until SetEvictionManager has been called.
+func (m *managerImpl) startPressureDrainMonitor() {
+	go wait.Until(m.checkUnrecoverablePressure, unrecoverablePressureCheckInterval, wait.NeverStop)
+}
+
+// checkUnrecoverablePressure tracks how long disk and PID pressure have held continuously, and
+// drains the node the first time either has held for at least unrecoverablePressureDrainThreshold.
+func (m *managerImpl) checkUnrecoverablePressure() {
+	if m.evictionManager == nil || m.drainedForPressure {
+		return
+	}
+
+	now := m.clock.Now()
+	m.diskPressureSince = sinceOrZero(now, m.diskPressureSince, m.evictionManager.IsUnderDiskPressure())
+	m.pidPressureSince = sinceOrZero(now, m.pidPressureSince, m.evictionManager.IsUnderPIDPressure())
+
+	reason := unrecoverablePressureReason(now, m.diskPressureSince, m.pidPressureSince)
+	if reason == "" {
+		return
+	}
+
+	glog.Warningf("node drain: %s has held for at least %s, draining node %q", reason, unrecoverablePressureDrainThreshold, m.nodeName)
+	m.drainedForPressure = true
+	go func() {
+		if err := m.Drain(DrainPolicy{Timeout: unrecoverablePressureDrainTimeout, IgnoreDaemonSets: true}); err != nil {
+			glog.Errorf("node drain: draining node %q for sustained %s failed: %v", m.nodeName, reason, err)
+		}
+	}()
+}
+
+// sinceOrZero returns since unchanged if underPressure is still true and since was already set,
+// now if underPressure just became true, or the zero Time if underPressure is false.
+func sinceOrZero(now, since time.Time, underPressure bool) time.Time {
+	if !underPressure {
+		return time.Time{}
+	}
+	if since.IsZero() {
+		return now
+	}
+	return since
+}
+
+// unrecoverablePressureReason returns which pressure condition, if any, has held continuously for
+// at least unrecoverablePressureDrainThreshold as of now, given when each started (or the zero
+// Time if it isn't currently active). Returns "" if neither has crossed the threshold yet.
+func unrecoverablePressureReason(now, diskPressureSince, pidPressureSince time.Time) string {
+	switch {
+	case !diskPressureSince.IsZero() && now.Sub(diskPressureSince) >= unrecoverablePressureDrainThreshold:
+		return "DiskPressure"
+	case !pidPressureSince.IsZero() && now.Sub(pidPressureSince) >= unrecoverablePressureDrainThreshold:
+		return "PIDPressure"
+	}
+	return ""
+}
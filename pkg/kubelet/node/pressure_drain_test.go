@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+func TestUnrecoverablePressureReason(t *testing.T) {
+	now := time.Now()
+	justUnderThreshold := now.Add(-unrecoverablePressureDrainThreshold + time.Minute)
+	atOrOverThreshold := now.Add(-unrecoverablePressureDrainThreshold - time.Minute)
+
+	table := []struct {
+		name              string
+		diskPressureSince time.Time
+		pidPressureSince  time.Time
+		want              string
+	}{
+		{"no pressure", time.Time{}, time.Time{}, ""},
+		{"disk pressure under threshold", justUnderThreshold, time.Time{}, ""},
+		{"disk pressure over threshold", atOrOverThreshold, time.Time{}, "DiskPressure"},
+		{"pid pressure over threshold", time.Time{}, atOrOverThreshold, "PIDPressure"},
+		{"disk pressure wins when both are over threshold", atOrOverThreshold, atOrOverThreshold, "DiskPressure"},
+	}
+
+	for _, item := range table {
+		if got := unrecoverablePressureReason(now, item.diskPressureSince, item.pidPressureSince); got != item.want {
+			t.Errorf("%s: unrecoverablePressureReason() = %q, want %q", item.name, got, item.want)
+		}
+	}
+}
+
+func TestSinceOrZero(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	if got := sinceOrZero(now, time.Time{}, false); !got.IsZero() {
+		t.Errorf("expected sinceOrZero to stay zero while underPressure is false, got %v", got)
+	}
+	if got := sinceOrZero(now, time.Time{}, true); got != now {
+		t.Errorf("expected sinceOrZero to start tracking at now once underPressure becomes true, got %v, want %v", got, now)
+	}
+	if got := sinceOrZero(now, earlier, true); got != earlier {
+		t.Errorf("expected sinceOrZero to preserve the original start time while underPressure stays true, got %v, want %v", got, earlier)
+	}
+	if got := sinceOrZero(now, earlier, false); !got.IsZero() {
+		t.Errorf("expected sinceOrZero to reset once underPressure clears, got %v", got)
+	}
+}
+
+// TestCheckUnrecoverablePressureTracksOnset verifies checkUnrecoverablePressure starts tracking
+// disk pressure onset and is a no-op until SetEvictionManager has wired an eviction manager in.
+func TestCheckUnrecoverablePressureTracksOnset(t *testing.T) {
+	m := &managerImpl{clock: util.RealClock{}}
+
+	m.checkUnrecoverablePressure()
+	if !m.diskPressureSince.IsZero() || m.drainedForPressure {
+		t.Fatalf("expected checkUnrecoverablePressure to no-op with no eviction manager wired")
+	}
+
+	evictor := &fakePressureEvictionManager{diskPressure: true}
+	m.SetEvictionManager(evictor)
+	m.checkUnrecoverablePressure()
+
+	if m.diskPressureSince.IsZero() {
+		t.Errorf("expected checkUnrecoverablePressure to start tracking disk pressure onset once an eviction manager reports it")
+	}
+	if m.drainedForPressure {
+		t.Errorf("expected a single observation just under the threshold to not yet trigger a drain")
+	}
+}
@@ -39,21 +39,75 @@ func (f *NodeStatusFuncs) AddNodeStatusFunc(a NodeStatusFunc) {
 	*f = append(*f, a)
 }
 
+// NodeDrainFunc is a function invoked as one stage of a node drain, given the node being drained
+// and the DrainPolicy governing the drain.
+type NodeDrainFunc func(node *api.Node, policy DrainPolicy) error
+
+// NodeDrainTarget maintains a list of funcs to invoke when draining the node.
+type NodeDrainTarget interface {
+	// AddNodeDrainFunc adds the specified function, run after the built-in cordon/evict/finalize
+	// stages of Drain.
+	AddNodeDrainFunc(nodeDrainFunc NodeDrainFunc)
+}
+
+// NodeDrainFuncs maintains a list of funcs to invoke when draining the node.
+type NodeDrainFuncs []NodeDrainFunc
+
+// AddNodeDrainFunc adds the specified function.
+func (f *NodeDrainFuncs) AddNodeDrainFunc(a NodeDrainFunc) {
+	*f = append(*f, a)
+}
+
+// DrainPolicy controls how Manager.Drain cordons the node and evicts its pods before the manager
+// reports the node NotReady.
+type DrainPolicy struct {
+	// GracePeriodSeconds overrides the termination grace period used for pods deleted as a
+	// fallback once an eviction is repeatedly refused. A negative value leaves each pod's own
+	// grace period unchanged.
+	GracePeriodSeconds int64
+	// Timeout bounds the overall drain: once it elapses, Drain stops waiting on remaining pods
+	// and moves on to posting the final node status.
+	Timeout time.Duration
+	// IgnoreDaemonSets allows Drain to proceed, leaving DaemonSet-owned pods running, instead of
+	// failing when it encounters one.
+	IgnoreDaemonSets bool
+	// DeleteLocalData allows Drain to evict pods that use a local emptyDir volume, which would
+	// otherwise be preserved by refusing to evict them.
+	DeleteLocalData bool
+	// Force allows Drain to delete pods that aren't managed by a controller, instead of failing
+	// when it encounters one.
+	Force bool
+}
+
 // Manager is responsible for updating node status at specified frequency.
 type Manager interface {
 	NodeStatusTarget
+	NodeDrainTarget
 
 	// Start the node status manager.
-	// nodeStatusUpdateFrequency specifies how often kubelet posts node status to master.
-	// Note: be cautious when changing the constant, it must work with nodeMonitorGracePeriod
+	// heartbeatFrequency specifies, when the NodeLease feature is enabled, how often kubelet checks
+	// whether a full node status report is due; the Lease itself is renewed every 1/4 of the Lease
+	// duration regardless of heartbeatFrequency. When NodeLease is disabled, heartbeatFrequency is
+	// not used and no separate heartbeat (such as a LastHeartbeatTime patch) is sent: the full node
+	// status report is the only proof-of-life.
+	// statusReportFrequency specifies how often kubelet posts a full node status to master when
+	// nothing else has marked it dirty.
+	// Note: be cautious when changing either constant, they must work with nodeMonitorGracePeriod
 	// in nodecontroller. There are several constraints:
-	// 1. nodeMonitorGracePeriod must be N times more than nodeStatusUpdateFrequency, where
-	//    N means number of retries allowed for kubelet to post node status. It is pointless
-	//    to make nodeMonitorGracePeriod be less than nodeStatusUpdateFrequency, since there
-	//    will only be fresh values from Kubelet at an interval of nodeStatusUpdateFrequency.
-	//    The constant must be less than podEvictionTimeout.
-	// 2. nodeStatusUpdateFrequency needs to be large enough for kubelet to generate node
-	//    status. Kubelet may fail to update node status reliably if the value is too small,
-	//    as it takes time to gather all necessary node information.
-	Start(nodeStatusUpdateFrequency time.Duration)
+	// 1. nodeMonitorGracePeriod must be N times more than the proof-of-life interval (the Lease
+	//    renewal period, or statusReportFrequency without NodeLease), where N means number of
+	//    retries allowed for kubelet to post a heartbeat. It is pointless to make
+	//    nodeMonitorGracePeriod less than that interval, since there will only be fresh
+	//    proof-of-life from kubelet that often. The constant must be less than podEvictionTimeout.
+	// 2. statusReportFrequency needs to be large enough for kubelet to generate node status.
+	//    Kubelet may fail to update node status reliably if the value is too small, as it takes
+	//    time to gather all necessary node information.
+	Start(heartbeatFrequency, statusReportFrequency time.Duration)
+
+	// Drain cordons the node, evicts its pods in priority order honoring policy, and once the
+	// drain completes (or policy.Timeout's overall deadline expires) posts a final
+	// Ready=False,Reason=NodeShuttingDown status. Within this package the only caller is the
+	// pressure monitor in pressure_drain.go, which drains once disk or PID pressure reported by the
+	// eviction manager persists; no shutdown-signal handler or RPC here calls it.
+	Drain(policy DrainPolicy) error
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package install
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,17 +34,28 @@ import (
 	"k8s.io/kubernetes/pkg/quota/generic"
 )
 
-// NewDynamicRegistry returns a registry of quota evaluators.
-// If a shared informer factory is provided, it is used by evaluators
-// rather than performing direct queries.
-func NewDynamicRegistry(discoveryResourcesFn generic.DiscoveryResourcesFunc, kubeClient clientset.Interface, f informers.SharedInformerFactory) (quota.Registry, error) {
+// DynamicListFunc lists resources of the given GroupVersionResource in a namespace. It is the
+// fallback list source for a discovered resource's ObjectCountEvaluator when no
+// SharedInformerFactory is available to back it with a cache.
+type DynamicListFunc func(gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions) ([]runtime.Object, error)
+
+// NewDynamicRegistry returns a registry of quota evaluators that covers every namespaced, creatable,
+// deletable resource returned by discoveryResourcesFn, in addition to the bespoke evaluators known to
+// core.NewRegistry. Discovered evaluators are generic object-count evaluators; where a bespoke core
+// evaluator exists for the same GroupKind (e.g. it enforces requests/limits, not just a count), the
+// bespoke evaluator wins.
+//
+// If a shared informer factory is provided, discovered evaluators list through it rather than
+// performing direct queries; otherwise dynamicListFn is used, and restMapper resolves the internal
+// GroupKind each discovered GroupVersionResource evaluates quota for.
+func NewDynamicRegistry(discoveryResourcesFn generic.DiscoveryResourcesFunc, kubeClient clientset.Interface, f informers.SharedInformerFactory, restMapper meta.RESTMapper, dynamicListFn DynamicListFunc) (quota.Registry, error) {
 	resources, err := discoveryResourcesFn()
 	if err != nil {
 		return nil, err
 	}
 
 	// any resource that can be created and deleted can be managed by quota
-	quotableGroupVersionResources := []schema.GroupKind{}
+	quotableResources := []schema.GroupVersionResource{}
 	for _, item := range resources {
 		gv, err := schema.ParseGroupVersion(item.GroupVersion)
 		if err != nil {
@@ -63,37 +78,76 @@ func NewDynamicRegistry(discoveryResourcesFn generic.DiscoveryResourcesFunc, kub
 				glog.V(6).Infof("Skipping resource %v because it cannot be deleted.", gvr)
 				continue
 			}
-			quotableGroupVersionResources = append(quotableGroupVersionResources, gvr)
+			quotableResources = append(quotableResources, gvr)
 		}
 	}
 
-	evaluators := map[schema.GroupKind]Evaluator{}
-	for _, item := range quotableGroupVersionResources {
+	discovered := map[schema.GroupKind]quota.Evaluator{}
+	for _, gvr := range quotableResources {
+		groupKind, err := groupKindFor(restMapper, gvr)
+		if err != nil {
+			glog.Errorf("Failed to resolve GroupKind for %v, skipping: %v", gvr, err)
+			continue
+		}
 
+		gvr := gvr // capture for the closures below
+		var listFuncByNamespace generic.ListFuncByNamespace
 		if f != nil {
-			genericInformer, err := f.ForResource(item)
+			genericInformer, err := f.ForResource(gvr)
 			if err != nil {
 				return nil, err
 			}
-			listFuncByNamespace := func(namespace string, options metav1.ListOptions) ([]runtime.Object, error) {
-				lister := genericInformer.Lister().ByNamespace(namespace)
-				return lister.List(labels.Everything())
+			listFuncByNamespace = func(namespace string, options metav1.ListOptions) ([]runtime.Object, error) {
+				return genericInformer.Lister().ByNamespace(namespace).List(labels.Everything())
 			}
 		} else {
+			listFuncByNamespace = func(namespace string, options metav1.ListOptions) ([]runtime.Object, error) {
+				return dynamicListFn(gvr, namespace, options)
+			}
 		}
-		// type ListFuncByNamespace func(namespace string, options metav1.ListOptions) ([]runtime.Object, error)
 
-		evaluator := generic.ObjectCountEvaluator{
-			AllowCreateOnUpdate: false,               // TODO: this is not discoverable
-			InternalGroupKind:   api.Kind(""),        // TODO: need to have this from discovery above
-			ResourceName:        "test",              // TODO: need to have this generated safely from discovery above
-			ListFuncByNamespace: listFuncByNamespace, // TODO: need to create this from something
+		discovered[groupKind] = &generic.ObjectCountEvaluator{
+			AllowCreateOnUpdate: false,
+			InternalGroupKind:   groupKind,
+			ResourceName:        quotaResourceName(gvr),
+			ListFuncByNamespace: listFuncByNamespace,
 		}
-		// TODo add to list of evaluators
 	}
 
-	// TODO: merge evaluators above with bespoke evaluators
-	return core.NewRegistry(kubeClient, f), nil
+	// bespoke core evaluators (e.g. pods, services) understand more than a plain object count, so
+	// they take precedence over the generic evaluator auto-generated for the same GroupKind.
+	coreRegistry := core.NewRegistry(kubeClient, f)
+	merged := map[schema.GroupKind]quota.Evaluator{}
+	for groupKind, evaluator := range discovered {
+		merged[groupKind] = evaluator
+	}
+	for groupKind, evaluator := range coreRegistry.Evaluators() {
+		merged[groupKind] = evaluator
+	}
+
+	evaluators := make([]quota.Evaluator, 0, len(merged))
+	for _, evaluator := range merged {
+		evaluators = append(evaluators, evaluator)
+	}
+	return generic.NewRegistry(evaluators), nil
+}
+
+// groupKindFor resolves the internal GroupKind that a discovered GroupVersionResource belongs to
+func groupKindFor(restMapper meta.RESTMapper, gvr schema.GroupVersionResource) (schema.GroupKind, error) {
+	gvk, err := restMapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+	return gvk.GroupKind(), nil
+}
+
+// quotaResourceName derives a stable quota resource name for a discovered GroupVersionResource, e.g.
+// "count/deployments.apps" for apps/v1 deployments, or "count/pods" for the legacy core group.
+func quotaResourceName(gvr schema.GroupVersionResource) api.ResourceName {
+	if len(gvr.Group) == 0 {
+		return api.ResourceName(fmt.Sprintf("count/%s", gvr.Resource))
+	}
+	return api.ResourceName(fmt.Sprintf("count/%s.%s", gvr.Resource, gvr.Group))
 }
 
 // NewRegistry returns a registry of quota evaluators.
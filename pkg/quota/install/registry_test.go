@@ -0,0 +1,154 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/quota/generic"
+)
+
+// fakeRESTMapper resolves exactly the GroupVersionResources registered with it, and is otherwise
+// unimplemented since NewDynamicRegistry only ever calls KindFor.
+type fakeRESTMapper struct {
+	kinds map[schema.GroupVersionResource]schema.GroupVersionKind
+}
+
+func (m *fakeRESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, found := m.kinds[resource]
+	if !found {
+		return schema.GroupVersionKind{}, fmt.Errorf("no kind registered for %v", resource)
+	}
+	return gvk, nil
+}
+
+func (m *fakeRESTMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	gvk, err := m.KindFor(resource)
+	if err != nil {
+		return nil, err
+	}
+	return []schema.GroupVersionKind{gvk}, nil
+}
+func (m *fakeRESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	return input, nil
+}
+func (m *fakeRESTMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	return []schema.GroupVersionResource{input}, nil
+}
+func (m *fakeRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (m *fakeRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (m *fakeRESTMapper) ResourceSingularizer(resource string) (string, error) {
+	return resource, nil
+}
+
+func TestQuotaResourceName(t *testing.T) {
+	cases := []struct {
+		gvr      schema.GroupVersionResource
+		expected string
+	}{
+		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}, "count/pods"},
+		{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "count/deployments.apps"},
+		{schema.GroupVersionResource{Group: "widgets.example.com", Version: "v1", Resource: "widgets"}, "count/widgets.widgets.example.com"},
+	}
+	for _, c := range cases {
+		if actual := string(quotaResourceName(c.gvr)); actual != c.expected {
+			t.Errorf("quotaResourceName(%v) = %q, want %q", c.gvr, actual, c.expected)
+		}
+	}
+}
+
+// TestNewDynamicRegistryCustomResource verifies that a namespaced, creatable, deletable custom
+// resource surfaced by discovery is registered as a generic count evaluator under a stable name, and
+// that its ListFuncByNamespace falls back to the supplied DynamicListFunc when no SharedInformerFactory
+// is available.
+func TestNewDynamicRegistryCustomResource(t *testing.T) {
+	widgetGVR := schema.GroupVersionResource{Group: "widgets.example.com", Version: "v1", Resource: "widgets"}
+	widgetGVK := schema.GroupVersionKind{Group: "widgets.example.com", Version: "v1", Kind: "Widget"}
+
+	discoveryResourcesFn := func() ([]*metav1.APIResourceList, error) {
+		return []*metav1.APIResourceList{
+			{
+				GroupVersion: "widgets.example.com/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"create", "delete", "get", "list"}},
+					{Name: "widgets/status", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"get", "update"}},
+				},
+			},
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "componentstatuses", Namespaced: false, Kind: "ComponentStatus", Verbs: metav1.Verbs{"get", "list"}},
+				},
+			},
+		}, nil
+	}
+
+	restMapper := &fakeRESTMapper{kinds: map[schema.GroupVersionResource]schema.GroupVersionKind{
+		widgetGVR: widgetGVK,
+	}}
+
+	listCalls := 0
+	dynamicListFn := func(gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions) ([]runtime.Object, error) {
+		listCalls++
+		if gvr != widgetGVR {
+			t.Errorf("unexpected list of %v", gvr)
+		}
+		return []runtime.Object{}, nil
+	}
+
+	registry, err := NewDynamicRegistry(discoveryResourcesFn, fake.NewSimpleClientset(), nil, restMapper, dynamicListFn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	evaluator, found := registry.Evaluators()[widgetGVK.GroupKind()]
+	if !found {
+		t.Fatalf("expected an evaluator for %v, evaluators were: %v", widgetGVK.GroupKind(), registry.Evaluators())
+	}
+	if evaluator.GroupKind() != widgetGVK.GroupKind() {
+		t.Errorf("evaluator.GroupKind() = %v, want %v", evaluator.GroupKind(), widgetGVK.GroupKind())
+	}
+
+	objectCountEvaluator, ok := evaluator.(*generic.ObjectCountEvaluator)
+	if !ok {
+		t.Fatalf("expected a *generic.ObjectCountEvaluator, got %T", evaluator)
+	}
+	if objectCountEvaluator.ResourceName != "count/widgets.widgets.example.com" {
+		t.Errorf("ResourceName = %q, want %q", objectCountEvaluator.ResourceName, "count/widgets.widgets.example.com")
+	}
+
+	if _, err := objectCountEvaluator.ListFuncByNamespace("default", metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error listing widgets: %v", err)
+	}
+	if listCalls != 1 {
+		t.Errorf("expected ListFuncByNamespace to use dynamicListFn exactly once, got %d calls", listCalls)
+	}
+
+	if _, found := registry.Evaluators()[schema.GroupKind{Kind: "ComponentStatus"}]; found {
+		t.Errorf("componentstatuses is not namespaced and should not have been registered")
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// Max returns a new ResourceList holding, for every resource present in a or b, the larger of the
+// two quantities (a resource absent from one list is treated as zero). Both admission-time and
+// controller-time quota usage computations call this so a pod's effective footprint is computed
+// identically in both places.
+func Max(a, b api.ResourceList) api.ResourceList {
+	result := api.ResourceList{}
+	for name, quantity := range a {
+		result[name] = quantity
+	}
+	for name, quantity := range b {
+		if existing, found := result[name]; !found || quantity.Cmp(existing) > 0 {
+			result[name] = quantity
+		}
+	}
+	return result
+}
@@ -18,8 +18,10 @@ package resourcecontroller
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
 	etcdgeneric "github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic/etcd"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/resourceobservation"
@@ -30,11 +32,26 @@ import (
 type Registry interface {
 	generic.Registry
 	resourceobservation.Registry
+
+	// GetScale returns the named resource controller's scale subresource.
+	GetScale(ctx api.Context, name string) (*api.Scale, error)
+	// UpdateScale persists scale as the named resource controller's spec.Replicas. Any other field
+	// of scale besides spec.Replicas and metadata.ResourceVersion is ignored rather than rejected.
+	UpdateScale(ctx api.Context, name string, scale *api.Scale) error
+	// ControllersWithSelector returns the namespace/name of every currently known resource
+	// controller whose spec.Selector is identical to selector, via the registry's
+	// selectorIndex, so a caller can check for a selector collision without a full list scan.
+	ControllersWithSelector(selector labels.Selector) map[string]bool
 }
 
 // registry implements custom changes to generic.Etcd.
 type registry struct {
 	*etcdgeneric.Etcd
+
+	// selectorIndex maps a hash of a resource controller's spec.Selector to the namespaced names
+	// of the controllers sharing that selector, so REST.Create can reject a selector collision
+	// via a direct lookup instead of listing and re-deriving every controller's selector.
+	selectorIndex *selectorIndex
 }
 
 // Create stores the object with a ttl, so that events don't stay in the system forever.
@@ -53,13 +70,150 @@ func (r *registry) ApplyObservation(ctx api.Context, observation *api.ResourceOb
 	ctrl := obj.(*api.ResourceController)
 	ctrl.ResourceVersion = observation.ResourceVersion
 	ctrl.Status = observation.Status
+	if err := r.Update(ctx, ctrl.Name, ctrl); err != nil {
+		return err
+	}
+	r.selectorIndex.update(ctx, ctrl)
+	return nil
+}
+
+// Create stores a new resource controller and indexes it by its selector.
+func (r *registry) Create(ctx api.Context, id string, obj runtime.Object) error {
+	if err := r.Etcd.Create(ctx, id, obj); err != nil {
+		return err
+	}
+	if ctrl, ok := obj.(*api.ResourceController); ok {
+		r.selectorIndex.update(ctx, ctrl)
+	}
+	return nil
+}
+
+// Update persists obj and re-indexes it by its selector.
+func (r *registry) Update(ctx api.Context, id string, obj runtime.Object) error {
+	if err := r.Etcd.Update(ctx, id, obj); err != nil {
+		return err
+	}
+	if ctrl, ok := obj.(*api.ResourceController); ok {
+		r.selectorIndex.update(ctx, ctrl)
+	}
+	return nil
+}
+
+// ControllersWithSelector looks selector up in the selectorIndex directly, instead of listing
+// every resource controller and re-deriving each one's selector to compare.
+func (r *registry) ControllersWithSelector(selector labels.Selector) map[string]bool {
+	return r.selectorIndex.matchingNames(selector)
+}
+
+// GetScale returns the scale subresource view of the named resource controller.
+func (r *registry) GetScale(ctx api.Context, name string) (*api.Scale, error) {
+	obj, err := r.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ctrl := obj.(*api.ResourceController)
+	return scaleFromResourceController(ctrl), nil
+}
+
+// UpdateScale applies scale.Spec.Replicas to the named resource controller. Any other field in
+// scale besides spec.Replicas and metadata.ResourceVersion is ignored, so an HPA-style controller
+// can never accidentally clobber the rest of the controller's spec through this endpoint.
+func (r *registry) UpdateScale(ctx api.Context, name string, scale *api.Scale) error {
+	obj, err := r.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	ctrl := obj.(*api.ResourceController)
+	if len(scale.ResourceVersion) == 0 {
+		return fmt.Errorf("a scale update must have a resourceVersion specified to ensure atomic updates")
+	}
+	ctrl.ResourceVersion = scale.ResourceVersion
+	ctrl.Spec.Replicas = scale.Spec.Replicas
 	return r.Update(ctx, ctrl.Name, ctrl)
 }
 
+// scaleFromResourceController projects a ResourceController's replica count and selector onto
+// the generic Scale subresource shape.
+func scaleFromResourceController(ctrl *api.ResourceController) *api.Scale {
+	return &api.Scale{
+		ObjectMeta: api.ObjectMeta{
+			Name:            ctrl.Name,
+			Namespace:       ctrl.Namespace,
+			ResourceVersion: ctrl.ResourceVersion,
+		},
+		Spec: api.ScaleSpec{
+			Replicas: ctrl.Spec.Replicas,
+		},
+		Status: api.ScaleStatus{
+			Replicas: ctrl.Status.Replicas,
+			Selector: ctrl.Spec.Selector,
+		},
+	}
+}
+
+// selectorIndex is an in-memory, incrementally maintained map from a selector hash to the
+// namespace/name of every resource controller sharing that selector. It trades a small amount of
+// memory for avoiding a linear selector evaluation across every existing controller on Create.
+type selectorIndex struct {
+	lock  sync.RWMutex
+	byKey map[string]map[string]bool
+}
+
+func newSelectorIndex() *selectorIndex {
+	return &selectorIndex{byKey: map[string]map[string]bool{}}
+}
+
+// update (re)indexes ctrl under its current selector, removing it from any selector it's no
+// longer under.
+func (s *selectorIndex) update(ctx api.Context, ctrl *api.ResourceController) {
+	if s == nil || ctrl == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	namespacedName := ctrl.Namespace + "/" + ctrl.Name
+	for _, names := range s.byKey {
+		delete(names, namespacedName)
+	}
+
+	key := labels.SelectorFromSet(ctrl.Spec.Selector).String()
+	if s.byKey[key] == nil {
+		s.byKey[key] = map[string]bool{}
+	}
+	s.byKey[key][namespacedName] = true
+}
+
+// matchingNames returns the namespace/name of every resource controller currently indexed under
+// selector.
+func (s *selectorIndex) matchingNames(selector labels.Selector) map[string]bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.byKey[selector.String()]
+}
+
+// rebuildSelectorIndex repopulates the index from every resource controller currently in etcd. It's called
+// once when the registry is constructed; after that the index is kept current incrementally by
+// Create, Update, and ApplyObservation.
+func (r *registry) rebuildSelectorIndex() {
+	ctx := api.NewDefaultContext()
+	obj, err := r.List(ctx, &generic.SelectionPredicate{Label: labels.Everything(), Field: labels.Everything()})
+	if err != nil {
+		return
+	}
+	list, ok := obj.(*api.ResourceControllerList)
+	if !ok {
+		return
+	}
+	for i := range list.Items {
+		r.selectorIndex.update(ctx, &list.Items[i])
+	}
+}
+
 // NewEtcdRegistry returns a registry which will store ResourceControllers in the given
 // EtcdHelper.
 func NewEtcdRegistry(h tools.EtcdHelper) Registry {
-	return &registry{
+	r := &registry{
 		Etcd: &etcdgeneric.Etcd{
 			NewFunc:      func() runtime.Object { return &api.ResourceController{} },
 			NewListFunc:  func() runtime.Object { return &api.ResourceControllerList{} },
@@ -70,5 +224,8 @@ func NewEtcdRegistry(h tools.EtcdHelper) Registry {
 			},
 			Helper: h,
 		},
+		selectorIndex: newSelectorIndex(),
 	}
+	r.rebuildSelectorIndex()
+	return r
 }
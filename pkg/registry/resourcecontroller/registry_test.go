@@ -0,0 +1,75 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+func TestSelectorIndexMatchingNames(t *testing.T) {
+	index := newSelectorIndex()
+	ctx := api.NewDefaultContext()
+
+	foo := &api.ResourceController{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "foo"}}
+	foo.Spec.Selector = map[string]string{"app": "web"}
+	bar := &api.ResourceController{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "bar"}}
+	bar.Spec.Selector = map[string]string{"app": "web"}
+	baz := &api.ResourceController{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "baz"}}
+	baz.Spec.Selector = map[string]string{"app": "db"}
+
+	index.update(ctx, foo)
+	index.update(ctx, bar)
+	index.update(ctx, baz)
+
+	webSelector := labels.SelectorFromSet(map[string]string{"app": "web"})
+	got := index.matchingNames(webSelector)
+	if !got["ns/foo"] || !got["ns/bar"] || got["ns/baz"] {
+		t.Fatalf("matchingNames(%v) = %v, want exactly ns/foo and ns/bar", webSelector, got)
+	}
+
+	// re-indexing foo under a new selector should remove it from the old entry.
+	foo.Spec.Selector = map[string]string{"app": "cache"}
+	index.update(ctx, foo)
+	got = index.matchingNames(webSelector)
+	if got["ns/foo"] {
+		t.Errorf("expected ns/foo to no longer match %v after being re-indexed, got %v", webSelector, got)
+	}
+}
+
+func TestSameScopes(t *testing.T) {
+	table := []struct {
+		name string
+		a    []api.ResourceControllerScope
+		b    []api.ResourceControllerScope
+		want bool
+	}{
+		{"both empty", nil, nil, true},
+		{"identical single scope", []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, true},
+		{"same scopes different order", []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort, api.ResourceControllerScopeTerminating}, []api.ResourceControllerScope{api.ResourceControllerScopeTerminating, api.ResourceControllerScopeBestEffort}, true},
+		{"different scopes", []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, []api.ResourceControllerScope{api.ResourceControllerScopeNotBestEffort}, false},
+		{"different lengths", []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort, api.ResourceControllerScopeTerminating}, false},
+	}
+
+	for _, item := range table {
+		if got := sameScopes(item.a, item.b); got != item.want {
+			t.Errorf("%s: sameScopes() = %v, want %v", item.name, got, item.want)
+		}
+	}
+}
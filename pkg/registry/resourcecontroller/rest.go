@@ -18,6 +18,7 @@ package resourcecontroller
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
@@ -29,6 +30,14 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
 )
 
+// controllersWithSelector is implemented by the registry's concrete *registry via its
+// selectorIndex. REST type-asserts for it rather than widening its own registry field to the
+// package's Registry interface, since not every generic.Registry backing a REST necessarily
+// maintains a selectorIndex.
+type controllersWithSelector interface {
+	ControllersWithSelector(selector labels.Selector) map[string]bool
+}
+
 // REST implements the RESTStorage interface for a ResourceControllers
 type REST struct {
 	registry generic.Registry
@@ -57,6 +66,9 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan apiserver.RE
 	if errs := validation.ValidateResourceController(resourceController); len(errs) > 0 {
 		return nil, errors.NewInvalid("resourceController", resourceController.Name, errs)
 	}
+	if err := rs.rejectDuplicateSelector(ctx, resourceController); err != nil {
+		return nil, err
+	}
 	return apiserver.MakeAsync(func() (runtime.Object, error) {
 		err := rs.registry.Create(ctx, resourceController.Name, resourceController)
 		if err != nil {
@@ -132,6 +144,87 @@ func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan apiserver.RE
 	}), nil
 }
 
+// rejectDuplicateSelector denies creating resourceController if another controller already exists
+// in the same namespace with an identical selector and identical scopes, since such a pair would
+// charge the same pods against quota twice for no observable difference. Two controllers sharing a
+// selector but differing in scope (e.g. one BestEffort, one NotBestEffort) are not a collision; see
+// resourcecontroller.PodMatchesController.
+func (rs *REST) rejectDuplicateSelector(ctx api.Context, resourceController *api.ResourceController) error {
+	if len(resourceController.Spec.Selector) == 0 {
+		return nil
+	}
+	indexed, ok := rs.registry.(controllersWithSelector)
+	if !ok {
+		return nil
+	}
+
+	namespacePrefix := resourceController.Namespace + "/"
+	selector := labels.SelectorFromSet(resourceController.Spec.Selector)
+	for namespacedName := range indexed.ControllersWithSelector(selector) {
+		if !strings.HasPrefix(namespacedName, namespacePrefix) {
+			continue
+		}
+		existingName := strings.TrimPrefix(namespacedName, namespacePrefix)
+		if existingName == resourceController.Name {
+			continue
+		}
+
+		obj, err := rs.registry.Get(ctx, existingName)
+		if err != nil {
+			continue
+		}
+		existing, ok := obj.(*api.ResourceController)
+		if !ok || !sameScopes(existing.Spec.Scopes, resourceController.Spec.Scopes) {
+			continue
+		}
+		return errors.NewConflict("resourceController", resourceController.Name,
+			fmt.Errorf("resource controller %q in namespace %q already covers the same selector and scopes", existing.Name, resourceController.Namespace))
+	}
+	return nil
+}
+
+// sameScopes reports whether a and b contain the same set of scopes, ignoring order and
+// duplicates.
+func sameScopes(a, b []api.ResourceControllerScope) bool {
+	setA := make(map[api.ResourceControllerScope]bool, len(a))
+	for _, scope := range a {
+		setA[scope] = true
+	}
+	setB := make(map[api.ResourceControllerScope]bool, len(b))
+	for _, scope := range b {
+		setB[scope] = true
+	}
+	if len(setA) != len(setB) {
+		return false
+	}
+	for scope := range setB {
+		if !setA[scope] {
+			return false
+		}
+	}
+	return true
+}
+
+// getAttrs indexes a ResourceController by its labels and by a handful of useful fields, so that
+// List and Watch can be filtered server-side via generic.SelectionPredicate rather than requiring
+// every client to fetch the full list and filter locally.
 func (rs *REST) getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
-	return labels.Set{}, labels.Set{}, nil
+	resourceController, ok := obj.(*api.ResourceController)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid object type %#v", obj)
+	}
+
+	objFields = labels.Set{
+		"metadata.name":      resourceController.Name,
+		"metadata.namespace": resourceController.Namespace,
+	}
+	// a ResourceController most commonly declares a single allowed group; expose its GroupBy so
+	// tooling can select, e.g. --field-selector spec.groupBy=Namespace. A controller that mixes
+	// multiple kinds of allowed group should be selected by label instead, since a field selector
+	// can only ever match the one value recorded here.
+	if len(resourceController.Spec.Allowed) > 0 {
+		objFields["spec.groupBy"] = string(resourceController.Spec.Allowed[0].GroupBy)
+	}
+
+	return labels.Set(resourceController.Labels), objFields, nil
 }
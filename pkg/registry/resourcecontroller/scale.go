@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// ScaleREST implements the /scale subresource for a ResourceController, letting a caller such as
+// an HPA-style controller read and adjust spec.Replicas without touching the rest of the
+// controller's spec.
+type ScaleREST struct {
+	registry Registry
+}
+
+// NewScaleREST returns a new ScaleREST.
+func NewScaleREST(registry Registry) *ScaleREST {
+	return &ScaleREST{registry: registry}
+}
+
+func (r *ScaleREST) New() runtime.Object {
+	return &api.Scale{}
+}
+
+// Get returns the named resource controller's scale subresource.
+func (r *ScaleREST) Get(ctx api.Context, name string) (runtime.Object, error) {
+	return r.registry.GetScale(ctx, name)
+}
+
+// Update persists the incoming scale's Spec.Replicas onto the named resource controller. Any
+// other field on the incoming object besides Spec.Replicas and ResourceVersion is ignored.
+func (r *ScaleREST) Update(ctx api.Context, obj runtime.Object) (<-chan apiserver.RESTResult, error) {
+	scale, ok := obj.(*api.Scale)
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("invalid object type %#v for resource controller scale", obj))
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		if err := r.registry.UpdateScale(ctx, scale.Name, scale); err != nil {
+			return nil, err
+		}
+		return r.registry.GetScale(ctx, scale.Name)
+	}), nil
+}
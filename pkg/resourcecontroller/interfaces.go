@@ -23,10 +23,16 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
 )
 
-// ObserverFunc makes an observation in the given namespace
+// ObserverFunc makes an observation for the given ResourceController. The full controller, rather
+// than just its namespace, is passed so a Func that walks pods/objects can apply the controller's
+// Spec.Scopes and Spec.Selector and only count what that specific controller's quota applies to.
 // The provided store is initialized with each periodic synchronization of the supplied namespace
 // It is useful for ensuring multiple client calls are not required to get the same data for each synchronization tick
-type ObserverFunc func(store cache.Store, namespace string) (*resource.Quantity, error)
+type ObserverFunc func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error)
+
+// ObserverNodeFunc makes an observation for the given node
+// The provided store is initialized with each periodic synchronization of the supplied node, mirroring ObserverFunc
+type ObserverNodeFunc func(store cache.Store, nodeName string) (*resource.Quantity, error)
 
 // ObserverFuncBinding associates an observer function with a group, rule, and resource
 type ObserverFuncBinding struct {
@@ -34,6 +40,59 @@ type ObserverFuncBinding struct {
 	RuleType     api.ResourceControllerRuleType
 	ResourceName api.ResourceName
 	Func         ObserverFunc
+
+	// PodFilter documents which pods Func counts toward its observation, for bindings whose Func
+	// walks pods. It has no effect on its own; Func is responsible for applying it.
+	PodFilter PodFilter
+}
+
+// PodFilter restricts which pods should be counted toward a pod-walking ObserverFunc's observation.
+type PodFilter struct {
+	// IncludePhases restricts counted pods to the given phases. If empty, every phase except
+	// PodSucceeded and PodFailed is included, mirroring how the scheduler ignores terminated pods.
+	IncludePhases []api.PodPhase
+	// RequireReady additionally restricts counted pods to those reporting a PodReady condition of True.
+	RequireReady bool
+}
+
+// Matches reports whether pod should be counted under this filter
+func (f PodFilter) Matches(pod api.Pod) bool {
+	phases := f.IncludePhases
+	if len(phases) == 0 {
+		phases = []api.PodPhase{api.PodPending, api.PodRunning, api.PodUnknown}
+	}
+	included := false
+	for _, phase := range phases {
+		if pod.Status.Phase == phase {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	if f.RequireReady && !isPodReady(pod) {
+		return false
+	}
+	return true
+}
+
+// isPodReady reports whether pod has a PodReady condition with status True
+func isPodReady(pod api.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == api.PodReady {
+			return condition.Status == api.ConditionTrue
+		}
+	}
+	return false
+}
+
+// ObserverNodeFuncBinding associates a node observer function with a rule and resource
+// GroupBy is always api.ResourceControllerGroupByNode for a node binding
+type ObserverNodeFuncBinding struct {
+	RuleType     api.ResourceControllerRuleType
+	ResourceName api.ResourceName
+	Func         ObserverNodeFunc
 }
 
 // Observer is a plug-in that groups a set of ObserverFuncBindings
@@ -41,5 +100,11 @@ type Observer interface {
 	ObserverFuncBindings() []ObserverFuncBinding
 }
 
+// NodeObserver is an optional extension to Observer for plug-ins that can aggregate usage per node
+// Plug-ins that have no node-scoped bindings need not implement this interface
+type NodeObserver interface {
+	ObserverNodeFuncBindings() []ObserverNodeFuncBinding
+}
+
 // Factory instantiates an Observer with a configured client
 type Factory func(client.Interface) (Observer, error)
@@ -0,0 +1,101 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+func readyPod(phase api.PodPhase, ready bool) api.Pod {
+	status := api.PodConditionStatus(api.ConditionFalse)
+	if ready {
+		status = api.ConditionTrue
+	}
+	return api.Pod{
+		Status: api.PodStatus{
+			Phase:      phase,
+			Conditions: []api.PodCondition{{Type: api.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestPodFilterMatchesDefaultExcludesTerminalPods(t *testing.T) {
+	filter := PodFilter{}
+
+	table := []struct {
+		phase api.PodPhase
+		want  bool
+	}{
+		{api.PodPending, true},
+		{api.PodRunning, true},
+		{api.PodUnknown, true},
+		{api.PodSucceeded, false},
+		{api.PodFailed, false},
+	}
+
+	for _, item := range table {
+		pod := readyPod(item.phase, true)
+		if got := filter.Matches(pod); got != item.want {
+			t.Errorf("phase %v: Matches() = %v, want %v", item.phase, got, item.want)
+		}
+	}
+}
+
+func TestPodFilterMatchesLifecycleTransition(t *testing.T) {
+	filter := PodFilter{}
+
+	// a pod transitioning Pending -> Running -> Succeeded should count toward usage while Pending
+	// and Running, then drop out once it reaches the terminal Succeeded phase
+	pending := readyPod(api.PodPending, false)
+	if !filter.Matches(pending) {
+		t.Errorf("expected a Pending pod to be counted")
+	}
+
+	running := readyPod(api.PodRunning, true)
+	if !filter.Matches(running) {
+		t.Errorf("expected a Running pod to be counted")
+	}
+
+	succeeded := readyPod(api.PodSucceeded, false)
+	if filter.Matches(succeeded) {
+		t.Errorf("expected a Succeeded pod to no longer be counted")
+	}
+}
+
+func TestPodFilterRequireReady(t *testing.T) {
+	filter := PodFilter{RequireReady: true}
+
+	if filter.Matches(readyPod(api.PodRunning, false)) {
+		t.Errorf("expected an unready Running pod to be excluded when RequireReady is set")
+	}
+	if !filter.Matches(readyPod(api.PodRunning, true)) {
+		t.Errorf("expected a ready Running pod to be counted when RequireReady is set")
+	}
+}
+
+func TestPodFilterIncludePhases(t *testing.T) {
+	filter := PodFilter{IncludePhases: []api.PodPhase{api.PodSucceeded}}
+
+	if !filter.Matches(readyPod(api.PodSucceeded, false)) {
+		t.Errorf("expected an explicit IncludePhases to override the default exclusion of Succeeded")
+	}
+	if filter.Matches(readyPod(api.PodRunning, true)) {
+		t.Errorf("expected Running to be excluded once IncludePhases no longer lists it")
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/golang/glog"
+)
+
+// leaderElectionAnnotationKey holds the marshaled leaderElectionRecord on the lock ResourceController
+const leaderElectionAnnotationKey = "resourcecontroller.kubernetes.io/leader"
+
+// leaderElectionRecord is persisted as JSON in the lock object's annotations so that any number of
+// ResourceManagers can race to become the single active synchronizer without a dedicated lock API
+type leaderElectionRecord struct {
+	HolderIdentity string    `json:"holderIdentity"`
+	LeaseDuration  int64     `json:"leaseDurationSeconds"`
+	AcquireTime    time.Time `json:"acquireTime"`
+	RenewTime      time.Time `json:"renewTime"`
+}
+
+// LeaderElectionConfig describes how a ResourceManager should contend for leadership before running
+// its synchronization loop, so that only one of several replicas observes and writes usage at a time
+type LeaderElectionConfig struct {
+	// Client is used to read and write the lock object
+	Client client.Interface
+	// LockNamespace and LockName identify the ResourceController used as the lock
+	LockNamespace, LockName string
+	// Identity is this replica's unique name, recorded as the lock holder while leading
+	Identity string
+	// LeaseDuration is how long a non-renewed lock is considered held by its last holder
+	LeaseDuration time.Duration
+	// RetryPeriod is how often to attempt to acquire or renew the lock
+	RetryPeriod time.Duration
+
+	// OnStartedLeading is called when this replica begins leading; it should block until leadership is lost
+	OnStartedLeading func(stop <-chan struct{})
+	// OnStoppedLeading is called when this replica stops leading, once OnStartedLeading returns; Run
+	// keeps retrying until it acquires the lock, so it is never called without leadership
+	OnStoppedLeading func()
+}
+
+// leaderElector contends for a single ResourceController lock object on behalf of a LeaderElectionConfig
+type leaderElector struct {
+	config   LeaderElectionConfig
+	observed leaderElectionRecord
+}
+
+// newLeaderElector validates the supplied config and returns a leaderElector ready to Run
+func newLeaderElector(config LeaderElectionConfig) (*leaderElector, error) {
+	if config.Identity == "" {
+		return nil, fmt.Errorf("LeaderElectionConfig.Identity is required")
+	}
+	if config.LeaseDuration == 0 {
+		config.LeaseDuration = 15 * time.Second
+	}
+	if config.RetryPeriod == 0 {
+		config.RetryPeriod = 5 * time.Second
+	}
+	return &leaderElector{config: config}, nil
+}
+
+// Run attempts to acquire the lock and, once acquired, calls OnStartedLeading and keeps renewing the
+// lock until renewal fails or is lost, at which point it calls OnStoppedLeading and returns
+func (le *leaderElector) Run() {
+	defer le.config.OnStoppedLeading()
+
+	for !le.tryAcquireOrRenew() {
+		glog.V(4).Infof("%v failed to acquire lock on %v/%v, retrying", le.config.Identity, le.config.LockNamespace, le.config.LockName)
+		time.Sleep(le.config.RetryPeriod)
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		for {
+			time.Sleep(le.config.RetryPeriod)
+			if !le.tryAcquireOrRenew() {
+				glog.Errorf("%v lost leadership on %v/%v", le.config.Identity, le.config.LockNamespace, le.config.LockName)
+				return
+			}
+		}
+	}()
+
+	le.config.OnStartedLeading(stop)
+}
+
+// tryAcquireOrRenew attempts to atomically acquire or renew the lock, returning true on success. It
+// relies on the ResourceController's ResourceVersion-based optimistic concurrency to guarantee that
+// only one caller can ever win a given acquire or renewal.
+func (le *leaderElector) tryAcquireOrRenew() bool {
+	now := time.Now()
+	lock, err := le.config.Client.ResourceControllers(le.config.LockNamespace).Get(le.config.LockName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			glog.Errorf("error retrieving resource controller lock %v/%v: %v", le.config.LockNamespace, le.config.LockName, err)
+			return false
+		}
+		lock = &api.ResourceController{
+			ObjectMeta: api.ObjectMeta{
+				Name:      le.config.LockName,
+				Namespace: le.config.LockNamespace,
+			},
+		}
+	} else {
+		record, err := decodeLeaderElectionRecord(lock)
+		if err == nil && record.HolderIdentity != le.config.Identity && now.Sub(record.RenewTime) < le.config.LeaseDuration {
+			// someone else holds a lock that has not yet expired
+			return false
+		}
+		le.observed = *record
+	}
+
+	record := leaderElectionRecord{
+		HolderIdentity: le.config.Identity,
+		LeaseDuration:  int64(le.config.LeaseDuration / time.Second),
+		RenewTime:      now,
+	}
+	if le.observed.HolderIdentity == le.config.Identity {
+		record.AcquireTime = le.observed.AcquireTime
+	} else {
+		record.AcquireTime = now
+	}
+
+	if err := encodeLeaderElectionRecord(lock, record); err != nil {
+		glog.Errorf("error encoding resource controller lock %v/%v: %v", le.config.LockNamespace, le.config.LockName, err)
+		return false
+	}
+
+	if len(lock.ResourceVersion) == 0 {
+		if _, err := le.config.Client.ResourceControllers(le.config.LockNamespace).Create(lock); err != nil {
+			glog.V(4).Infof("error creating resource controller lock %v/%v: %v", le.config.LockNamespace, le.config.LockName, err)
+			return false
+		}
+	} else {
+		if _, err := le.config.Client.ResourceControllers(le.config.LockNamespace).Update(lock); err != nil {
+			glog.V(4).Infof("error updating resource controller lock %v/%v: %v", le.config.LockNamespace, le.config.LockName, err)
+			return false
+		}
+	}
+
+	le.observed = record
+	return true
+}
+
+// decodeLeaderElectionRecord extracts the leaderElectionRecord persisted on a lock object's annotations
+func decodeLeaderElectionRecord(lock *api.ResourceController) (*leaderElectionRecord, error) {
+	raw, found := lock.Annotations[leaderElectionAnnotationKey]
+	if !found {
+		return &leaderElectionRecord{}, nil
+	}
+	record := &leaderElectionRecord{}
+	if err := json.Unmarshal([]byte(raw), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// encodeLeaderElectionRecord persists the leaderElectionRecord on a lock object's annotations
+func encodeLeaderElectionRecord(lock *api.ResourceController, record leaderElectionRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if lock.Annotations == nil {
+		lock.Annotations = map[string]string{}
+	}
+	lock.Annotations[leaderElectionAnnotationKey] = string(raw)
+	return nil
+}
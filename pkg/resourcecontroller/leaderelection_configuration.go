@@ -0,0 +1,87 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+)
+
+// ResourceLockResourceController is the only ResourceLock kind this package currently knows how to
+// acquire: the lock record is stored in a ResourceController's annotations, per leaderelection.go.
+const ResourceLockResourceController = "resourcecontroller"
+
+// LeaderElectionConfiguration is the command-line/config-file friendly description of whether and
+// how a ResourceManager replica should contend for leadership before running its synchronization
+// loop. It's serialized by the binary wiring this package up (e.g. a controller-manager flag
+// struct) and converted to a LeaderElectionConfig via NewLeaderElectionConfig.
+type LeaderElectionConfiguration struct {
+	// LeaderElect enables leader election. When false, every replica runs its own synchronize loop,
+	// which is only safe to do with a single replica.
+	LeaderElect bool `json:"leaderElect"`
+	// ResourceLock names the kind of lock object used to record leadership. Only
+	// ResourceLockResourceController is currently supported; this field exists so future lock kinds
+	// (e.g. "endpoints" or "configmap", as other controller-managers support) can be added without
+	// another breaking change to this struct.
+	ResourceLock string `json:"resourceLock"`
+	// LockNamespace and LockName identify the lock object.
+	LockNamespace string `json:"lockNamespace"`
+	LockName      string `json:"lockName"`
+	// LeaseDuration is how long a non-renewed lock is considered held by its last holder.
+	LeaseDuration time.Duration `json:"leaseDuration"`
+	// RenewDeadline is accepted for parity with other controller-managers' leader election
+	// configuration, but this package's leaderElector does not distinguish it from RetryPeriod; it
+	// is otherwise unused.
+	RenewDeadline time.Duration `json:"renewDeadline"`
+	// RetryPeriod is how often to attempt to acquire or renew the lock.
+	RetryPeriod time.Duration `json:"retryPeriod"`
+}
+
+// DefaultLeaderElectionConfiguration returns a LeaderElectionConfiguration with leader election
+// disabled and the defaults newLeaderElector otherwise fills in for a zero-valued LeaseDuration or
+// RetryPeriod.
+func DefaultLeaderElectionConfiguration() LeaderElectionConfiguration {
+	return LeaderElectionConfiguration{
+		ResourceLock:  ResourceLockResourceController,
+		LeaseDuration: 15 * time.Second,
+		RetryPeriod:   5 * time.Second,
+	}
+}
+
+// NewLeaderElectionConfig validates configuration and builds the LeaderElectionConfig
+// EnableLeaderElection expects, binding it to client and identity. It returns an error if
+// configuration names an unsupported ResourceLock kind.
+func NewLeaderElectionConfig(configuration LeaderElectionConfiguration, client client.Interface, identity string) (*LeaderElectionConfig, error) {
+	resourceLock := configuration.ResourceLock
+	if resourceLock == "" {
+		resourceLock = ResourceLockResourceController
+	}
+	if resourceLock != ResourceLockResourceController {
+		return nil, fmt.Errorf("unsupported leader election resourceLock %q: only %q is supported", resourceLock, ResourceLockResourceController)
+	}
+
+	return &LeaderElectionConfig{
+		Client:        client,
+		LockNamespace: configuration.LockNamespace,
+		LockName:      configuration.LockName,
+		Identity:      identity,
+		LeaseDuration: configuration.LeaseDuration,
+		RetryPeriod:   configuration.RetryPeriod,
+	}, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/golang/glog"
+)
+
+// defaultInformerResyncPeriod is how often namespaceInformer refreshes its cluster-wide cache.
+const defaultInformerResyncPeriod = 30 * time.Second
+
+// namespaceInformer maintains a periodically refreshed, cluster-wide cache of Pods,
+// ReplicationControllers, and ResourceControllers indexed by namespace, and enqueues onto a
+// namespaceQueue the namespaces whose objects changed since the previous refresh. It plays the role
+// a cache.Reflector/cache.NewInformer watch would play against a real apiserver watch connection,
+// built instead against this repo's existing List-only client.Interface — the same simplification
+// the ResourceLimits admission plugin's sharedInformerFactory already makes.
+type namespaceInformer struct {
+	client client.Interface
+	resync time.Duration
+	queue  *namespaceQueue
+
+	lock                              sync.RWMutex
+	resourceControllersByNamespace    map[string][]api.ResourceController
+	replicationControllersByNamespace map[string][]api.ReplicationController
+	podsByNamespace                   map[string][]api.Pod
+	signaturesByNamespace             map[string]string
+}
+
+func newNamespaceInformer(client client.Interface, resync time.Duration, queue *namespaceQueue) *namespaceInformer {
+	return &namespaceInformer{
+		client:                            client,
+		resync:                            resync,
+		queue:                             queue,
+		resourceControllersByNamespace:    map[string][]api.ResourceController{},
+		replicationControllersByNamespace: map[string][]api.ReplicationController{},
+		podsByNamespace:                   map[string][]api.Pod{},
+		signaturesByNamespace:             map[string]string{},
+	}
+}
+
+// Run performs an initial, synchronous refresh so that callers that wait on Run returning are
+// guaranteed a warm cache, then refreshes the cache every resync period until stopCh is closed.
+func (f *namespaceInformer) Run(stopCh <-chan struct{}) {
+	f.refresh()
+
+	go func() {
+		ticker := time.Tick(f.resync)
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker:
+				f.refresh()
+			}
+		}
+	}()
+}
+
+// refresh lists every watched kind across all namespaces, re-indexes it by namespace, and enqueues
+// any namespace whose signature (a summary of every object's name and resource version) changed
+// since the last refresh, so a single changed object only triggers a resync of its own namespace.
+func (f *namespaceInformer) refresh() {
+	resourceControllers, err := f.client.ResourceControllers(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("namespaceInformer: error listing resource controllers: %v", err)
+		return
+	}
+	replicationControllers, err := f.client.ReplicationControllers(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("namespaceInformer: error listing replication controllers: %v", err)
+		return
+	}
+	pods, err := f.client.Pods(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("namespaceInformer: error listing pods: %v", err)
+		return
+	}
+
+	resourceControllersByNamespace := map[string][]api.ResourceController{}
+	signatureByNamespace := map[string]string{}
+	for _, controller := range resourceControllers.Items {
+		resourceControllersByNamespace[controller.Namespace] = append(resourceControllersByNamespace[controller.Namespace], controller)
+		appendSignature(signatureByNamespace, controller.Namespace, "rc", controller.Name, controller.ResourceVersion)
+	}
+	replicationControllersByNamespace := map[string][]api.ReplicationController{}
+	for _, rc := range replicationControllers.Items {
+		replicationControllersByNamespace[rc.Namespace] = append(replicationControllersByNamespace[rc.Namespace], rc)
+		appendSignature(signatureByNamespace, rc.Namespace, "repl", rc.Name, rc.ResourceVersion)
+	}
+	podsByNamespace := map[string][]api.Pod{}
+	for _, pod := range pods.Items {
+		podsByNamespace[pod.Namespace] = append(podsByNamespace[pod.Namespace], pod)
+		appendSignature(signatureByNamespace, pod.Namespace, "pod", pod.Name, pod.ResourceVersion)
+	}
+
+	f.lock.Lock()
+	previous := f.signaturesByNamespace
+	f.resourceControllersByNamespace = resourceControllersByNamespace
+	f.replicationControllersByNamespace = replicationControllersByNamespace
+	f.podsByNamespace = podsByNamespace
+	f.signaturesByNamespace = signatureByNamespace
+	f.lock.Unlock()
+
+	changed := map[string]bool{}
+	for namespace, signature := range signatureByNamespace {
+		if previous[namespace] != signature {
+			changed[namespace] = true
+		}
+	}
+	for namespace := range previous {
+		if _, found := signatureByNamespace[namespace]; !found {
+			changed[namespace] = true
+		}
+	}
+	for namespace := range changed {
+		f.queue.Add(namespace)
+	}
+}
+
+// appendSignature folds kind/name/resourceVersion into namespace's running signature. Order of
+// folding doesn't matter for correctness as long as it's a pure function of the accumulated set of
+// (kind, name, resourceVersion) tuples seen so far.
+func appendSignature(signatures map[string]string, namespace, kind, name, resourceVersion string) {
+	signatures[namespace] += fmt.Sprintf("%s/%s=%s;", kind, name, resourceVersion)
+}
+
+// ResourceControllers returns the cached ResourceControllers in namespace.
+func (f *namespaceInformer) ResourceControllers(namespace string) []api.ResourceController {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.resourceControllersByNamespace[namespace]
+}
+
+// ReplicationControllers returns the cached ReplicationControllers in namespace.
+func (f *namespaceInformer) ReplicationControllers(namespace string) []api.ReplicationController {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.replicationControllersByNamespace[namespace]
+}
+
+// Pods returns the cached Pods in namespace.
+func (f *namespaceInformer) Pods(namespace string) []api.Pod {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.podsByNamespace[namespace]
+}
+
+// Namespaces returns every namespace the informer currently has ResourceControllers cached for
+// (namespaces holding only Pods or ReplicationControllers are not included), used to seed
+// the work queue with a full safety-net resync.
+func (f *namespaceInformer) Namespaces() []string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	namespaces := make([]string, 0, len(f.resourceControllersByNamespace))
+	for namespace := range f.resourceControllersByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import "sync"
+
+// namespaceQueue is a small work queue of namespace names (it applies no rate limiting), modeled on the
+// dirty/processing split client-go's workqueue.Type uses: a namespace Added while it is already
+// queued or being processed is coalesced into a single pending entry, and a namespace Added while
+// it is being processed is re-queued only once processing finishes, so a burst of changes to one
+// namespace never produces more than one extra sync.
+type namespaceQueue struct {
+	lock *sync.Mutex
+	cond *sync.Cond
+
+	queue      []string
+	dirty      map[string]bool
+	processing map[string]bool
+	shutDown   bool
+}
+
+func newNamespaceQueue() *namespaceQueue {
+	lock := &sync.Mutex{}
+	return &namespaceQueue{
+		lock:       lock,
+		cond:       sync.NewCond(lock),
+		dirty:      map[string]bool{},
+		processing: map[string]bool{},
+	}
+}
+
+// Add enqueues namespace if it isn't already pending. A namespace already queued or currently being
+// processed is marked dirty instead, so it is re-queued once Done is called for it.
+func (q *namespaceQueue) Add(namespace string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.shutDown || q.dirty[namespace] {
+		return
+	}
+	q.dirty[namespace] = true
+	if q.processing[namespace] {
+		return
+	}
+	q.queue = append(q.queue, namespace)
+	q.cond.Signal()
+}
+
+// Get blocks until a namespace is available or the queue is shut down.
+func (q *namespaceQueue) Get() (namespace string, shutdown bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.queue) == 0 && !q.shutDown {
+		q.cond.Wait()
+	}
+	if len(q.queue) == 0 {
+		return "", true
+	}
+
+	namespace = q.queue[0]
+	q.queue = q.queue[1:]
+	q.processing[namespace] = true
+	delete(q.dirty, namespace)
+	return namespace, false
+}
+
+// Done marks namespace as no longer being processed, re-queueing it if it was Added again while it
+// was being processed.
+func (q *namespaceQueue) Done(namespace string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	delete(q.processing, namespace)
+	if q.dirty[namespace] {
+		q.queue = append(q.queue, namespace)
+		q.cond.Signal()
+	}
+}
+
+// Len reports the current queue depth, for use as a metric.
+func (q *namespaceQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.queue)
+}
+
+// ShutDown causes every blocked and future Get to return immediately with shutdown=true.
+func (q *namespaceQueue) ShutDown() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.shutDown = true
+	q.cond.Broadcast()
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
@@ -39,6 +40,24 @@ type ResourceManager struct {
 
 	// used to make observations in given group
 	observerFuncs map[string]ObserverFunc
+
+	// used to make observations scoped to a single node, keyed by rule type and resource name
+	observerNodeFuncs map[string]ObserverNodeFunc
+
+	// leaderElection configures contention for a single active synchronize loop across replicas of
+	// this manager; it is nil when the manager should always run its own loop
+	leaderElection *LeaderElectionConfig
+
+	// informer maintains the namespace-indexed cache that backs event-driven syncs, and enqueues a
+	// namespace onto queue whenever a refresh sees one of its objects change
+	informer *namespaceInformer
+	// queue holds the namespaces pending an event-driven resync
+	queue *namespaceQueue
+	// workers is how many namespaces are synced concurrently
+	workers int
+
+	metricsLock     sync.Mutex
+	lastSyncLatency time.Duration
 }
 
 // NewResourceManager creates a new ResourceManager with specified client and list of observers
@@ -46,16 +65,28 @@ func NewResourceManager(kubeClient client.Interface, observers []Observer) *Reso
 
 	// build the map of observations funcs that can make an observation
 	observerFuncs := make(map[string]ObserverFunc)
+	observerNodeFuncs := make(map[string]ObserverNodeFunc)
 	for _, observer := range observers {
 		bindings := observer.ObserverFuncBindings()
 		for _, binding := range bindings {
 			observerFuncs[observerFuncKey(binding.GroupBy, binding.RuleType, binding.ResourceName)] = binding.Func
 		}
+		if nodeObserver, ok := observer.(NodeObserver); ok {
+			for _, binding := range nodeObserver.ObserverNodeFuncBindings() {
+				observerNodeFuncs[nodeObserverFuncKey(binding.RuleType, binding.ResourceName)] = binding.Func
+			}
+		}
 	}
 
+	queue := newNamespaceQueue()
+
 	rm := &ResourceManager{
-		kubeClient:    kubeClient,
-		observerFuncs: observerFuncs,
+		kubeClient:        kubeClient,
+		observerFuncs:     observerFuncs,
+		observerNodeFuncs: observerNodeFuncs,
+		informer:          newNamespaceInformer(kubeClient, defaultInformerResyncPeriod, queue),
+		queue:             queue,
+		workers:           defaultSyncWorkers,
 	}
 
 	// set the synchronization handler
@@ -63,27 +94,112 @@ func NewResourceManager(kubeClient client.Interface, observers []Observer) *Reso
 	return rm
 }
 
-// Run begins watching and syncing.
+// defaultSyncWorkers is how many namespaces ResourceManager.Run syncs concurrently.
+const defaultSyncWorkers = 5
+
+// EnableLeaderElection configures rm to only run its synchronization loop while it holds the named
+// ResourceController lock, so that multiple replicas of a ResourceManager can run for availability
+// without racing to write conflicting usage observations. It must be called before Run.
+func (rm *ResourceManager) EnableLeaderElection(config LeaderElectionConfig) {
+	rm.leaderElection = &config
+}
+
+// Run starts the namespace informer and a pool of sync workers that consume the namespaces it
+// enqueues, and then blocks; it returns only if leader election is enabled with a config
+// newLeaderElector rejects. period's tick is now a safety-net resync: it re-enqueues
+// every namespace the informer knows about, rather than driving the sync loop itself, so a change
+// that was missed or coalesced by the informer is still eventually corrected. If leader election
+// has been enabled via EnableLeaderElection, the informer and workers only run while rm holds the
+// configured lock; losing the lock stops them, and regaining it restarts them.
 func (rm *ResourceManager) Run(period time.Duration) {
 	rm.syncTime = time.Tick(period)
-	go util.Forever(func() { rm.synchronize() }, period)
-}
 
-func (rm *ResourceManager) synchronize() {
-	var resourceControllers []api.ResourceController
-	list, err := rm.kubeClient.ResourceControllers(api.NamespaceAll).List(labels.Everything())
+	start := func(stop <-chan struct{}) {
+		// ShutDown is permanent, so every start gets a fresh queue rather than the one a previous
+		// leadership term shut down.
+		queue := newNamespaceQueue()
+		rm.queue = queue
+		rm.informer.queue = queue
+
+		informerStop := make(chan struct{})
+		go rm.informer.Run(informerStop)
+
+		for i := 0; i < rm.workers; i++ {
+			go rm.runWorker(queue)
+		}
+
+		go func() {
+			for {
+				select {
+				case <-stop:
+					close(informerStop)
+					queue.ShutDown()
+					return
+				case <-rm.syncTime:
+					rm.enqueueAllNamespaces()
+				}
+			}
+		}()
+	}
+
+	if rm.leaderElection == nil {
+		stop := make(chan struct{})
+		start(stop)
+		<-stop
+		return
+	}
+
+	config := *rm.leaderElection
+	config.OnStartedLeading = func(stop <-chan struct{}) {
+		start(stop)
+		<-stop
+	}
+	config.OnStoppedLeading = func() {
+		glog.Infof("%v stopped leading resource controller synchronization", config.Identity)
+	}
+
+	elector, err := newLeaderElector(config)
 	if err != nil {
-		glog.Errorf("Synchronization error: %v (%#v)", err, err)
+		glog.Errorf("unable to start leader election for resource controller synchronization: %v", err)
+		return
+	}
+	util.Forever(elector.Run, 0)
+}
+
+// runWorker processes namespaces from queue until it is shut down.
+func (rm *ResourceManager) runWorker(queue *namespaceQueue) {
+	for {
+		namespace, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		rm.processNamespace(namespace)
+		queue.Done(namespace)
+	}
+}
+
+// enqueueAllNamespaces re-enqueues every namespace the informer has ResourceControllers cached for; it backs
+// the periodic safety-net resync.
+func (rm *ResourceManager) enqueueAllNamespaces() {
+	for _, namespace := range rm.informer.Namespaces() {
+		rm.queue.Add(namespace)
 	}
-	resourceControllers = list.Items
+}
+
+// processNamespace syncs every ResourceController cached for namespace, recording how long the
+// sync took for SyncLatency.
+func (rm *ResourceManager) processNamespace(namespace string) {
+	start := time.Now()
+	defer func() { rm.recordSyncLatency(time.Now().Sub(start)) }()
+
+	resourceControllers := rm.informer.ResourceControllers(namespace)
 	wg := sync.WaitGroup{}
 	wg.Add(len(resourceControllers))
 	for ix := range resourceControllers {
 		go func(ix int) {
 			defer wg.Done()
-			glog.V(4).Infof("periodic sync of %v.%v", resourceControllers[ix].Namespace, resourceControllers[ix].Name)
-			err := rm.syncHandler(resourceControllers[ix])
-			if err != nil {
+			glog.V(4).Infof("event-driven sync of %v.%v", resourceControllers[ix].Namespace, resourceControllers[ix].Name)
+			if err := rm.syncHandler(resourceControllers[ix]); err != nil {
 				glog.Errorf("Error synchronizing: %v", err)
 			}
 		}(ix)
@@ -91,12 +207,104 @@ func (rm *ResourceManager) synchronize() {
 	wg.Wait()
 }
 
+// QueueDepth returns the number of namespaces currently pending an event-driven sync.
+func (rm *ResourceManager) QueueDepth() int {
+	return rm.queue.Len()
+}
+
+func (rm *ResourceManager) recordSyncLatency(d time.Duration) {
+	rm.metricsLock.Lock()
+	rm.lastSyncLatency = d
+	rm.metricsLock.Unlock()
+}
+
+// SyncLatency returns how long the most recently completed namespace sync took.
+func (rm *ResourceManager) SyncLatency() time.Duration {
+	rm.metricsLock.Lock()
+	defer rm.metricsLock.Unlock()
+	return rm.lastSyncLatency
+}
+
 // observerFuncKey generates an unique key to map an ObserverFunc
 func observerFuncKey(groupBy api.ResourceControllerGroupBy, ruleType api.ResourceControllerRuleType, name api.ResourceName) string {
 	s := []string{string(groupBy), string(ruleType), string(name)}
 	return strings.Join(s, ".")
 }
 
+// nodeObserverFuncKey generates an unique key to map an ObserverNodeFunc
+func nodeObserverFuncKey(ruleType api.ResourceControllerRuleType, name api.ResourceName) string {
+	s := []string{string(ruleType), string(name)}
+	return strings.Join(s, ".")
+}
+
+// podStoreForNamespace builds a cache.Store pre-seeded with namespace's Pods from rm.informer's
+// cache, instead of the store an ObserverFunc receives starting out empty every sync.
+func (rm *ResourceManager) podStoreForNamespace(namespace string) cache.Store {
+	store := cache.NewStore()
+	for _, pod := range rm.informer.Pods(namespace) {
+		pod := pod
+		store.Add(&pod)
+	}
+	return store
+}
+
+// podStoreForNode is podStoreForNamespace narrowed to the Pods scheduled onto nodeName, for the
+// per-node observations observeNodeGroup makes.
+func (rm *ResourceManager) podStoreForNode(namespace, nodeName string) cache.Store {
+	store := cache.NewStore()
+	for _, pod := range rm.informer.Pods(namespace) {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		pod := pod
+		store.Add(&pod)
+	}
+	return store
+}
+
+// observeNodeGroup expands a single node-scoped allowed group into one allocated group per node in the
+// cluster, so a ResourceController can constrain aggregate requests/limits scheduled to each node
+func (rm *ResourceManager) observeNodeGroup(controller api.ResourceController, group api.ResourceControllerGroup) ([]api.ResourceControllerGroup, bool, error) {
+	nodeList, err := rm.kubeClient.Nodes().List(labels.Everything())
+	if err != nil {
+		return nil, false, err
+	}
+
+	dirty := false
+	observations := make([]api.ResourceControllerGroup, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		store := rm.podStoreForNode(controller.Namespace, node.Name)
+		latestObservation := api.ResourceControllerGroup{GroupBy: api.ResourceControllerGroupByNode, RuleType: group.RuleType, Node: node.Name, Resources: api.ResourceList{}}
+
+		for name, _ := range group.Resources {
+			observerFunc := rm.observerNodeFuncs[nodeObserverFuncKey(group.RuleType, name)]
+			if observerFunc == nil {
+				continue
+			}
+			quantity, err := observerFunc(store, node.Name)
+			if err != nil {
+				return nil, false, err
+			}
+			latestObservation.Resources[name] = *quantity
+
+			prevQuantity := previousNodeAllocation(controller.Status.Allocated, node.Name, group.RuleType, name)
+			dirty = dirty || (quantity.Value() != prevQuantity.Value())
+		}
+		observations = append(observations, latestObservation)
+	}
+	return observations, dirty, nil
+}
+
+// previousNodeAllocation finds the previously recorded allocation for the given node, rule, and resource
+func previousNodeAllocation(allocated []api.ResourceControllerGroup, nodeName string, ruleType api.ResourceControllerRuleType, name api.ResourceName) resource.Quantity {
+	for _, group := range allocated {
+		if group.GroupBy == api.ResourceControllerGroupByNode && group.Node == nodeName && group.RuleType == ruleType {
+			return group.Resources[name]
+		}
+	}
+	return resource.Quantity{}
+}
+
 // syncResourceController runs a complete sync of current status
 func (rm *ResourceManager) syncResourceController(controller api.ResourceController) error {
 	// Create a resource observation that is used relative to the viewed controller resource version
@@ -108,7 +316,7 @@ func (rm *ResourceManager) syncResourceController(controller api.ResourceControl
 		Status: api.ResourceControllerStatus{},
 	}
 	resourceObservation.Status.Allowed = make([]api.ResourceControllerGroup, len(controller.Spec.Allowed), len(controller.Spec.Allowed))
-	resourceObservation.Status.Allocated = make([]api.ResourceControllerGroup, len(controller.Spec.Allowed), len(controller.Spec.Allowed))
+	resourceObservation.Status.Allocated = make([]api.ResourceControllerGroup, 0, len(controller.Spec.Allowed))
 	copy(resourceObservation.Status.Allowed, controller.Spec.Allowed)
 
 	// prevAllocatedStatus is what we previously recorded as usage, we will use it to compare with our latest observations
@@ -118,12 +326,22 @@ func (rm *ResourceManager) syncResourceController(controller api.ResourceControl
 	// if this is our first observation, it will be dirty by default, since we need to make an observation
 	dirty := controller.Status.Allowed == nil || controller.Status.Allocated == nil
 
-	for index, group := range resourceObservation.Status.Allowed {
+	for _, group := range resourceObservation.Status.Allowed {
 
-		// create a store that can hold cached data so observer functions do not need to fetch the same data multiple times per synch loop
-		// for example, multiple observations may require a listing of all pods in a namespace, and we do not want to fetch them multiple
-		// times
-		store := cache.NewStore()
+		// a group scoped to a node is allocated once per node in the cluster, rather than once for the controller's namespace
+		if group.GroupBy == api.ResourceControllerGroupByNode {
+			nodeObservations, nodeDirty, err := rm.observeNodeGroup(controller, group)
+			if err != nil {
+				return err
+			}
+			dirty = dirty || nodeDirty
+			resourceObservation.Status.Allocated = append(resourceObservation.Status.Allocated, nodeObservations...)
+			continue
+		}
+
+		// store is pre-seeded from the namespace informer's cached Pods, so every observer walking
+		// pods in this sync sees the same snapshot without each one triggering its own List call
+		store := rm.podStoreForNamespace(controller.Namespace)
 
 		// latest observation is what is computed now
 		latestObservation := api.ResourceControllerGroup{GroupBy: group.GroupBy, RuleType: group.RuleType, Resources: api.ResourceList{}}
@@ -135,7 +353,7 @@ func (rm *ResourceManager) syncResourceController(controller api.ResourceControl
 			observerFunc := rm.observerFuncs[observerFuncKey(group.GroupBy, group.RuleType, name)]
 			if observerFunc != nil {
 
-				quantity, err := observerFunc(store, controller.Namespace)
+				quantity, err := observerFunc(store, controller)
 				if err != nil {
 					return err
 				}
@@ -148,7 +366,7 @@ func (rm *ResourceManager) syncResourceController(controller api.ResourceControl
 			}
 		}
 		// add it to the status
-		resourceObservation.Status.Allocated[index] = latestObservation
+		resourceObservation.Status.Allocated = append(resourceObservation.Status.Allocated, latestObservation)
 	}
 
 	if dirty {
@@ -180,3 +398,24 @@ func AllowedAndAllocated(status *api.ResourceControllerStatus) (map[api.Resource
 	}
 	return allowedGroupBy, allocatedGroupBy
 }
+
+// AllowedAndAllocatedForNode is like AllowedAndAllocated, but scoped to the ResourceControllerGroupByNode
+// groups that apply to the named node: every allowed node group, and the allocated node groups whose
+// Node is nodeName. AllowedAndAllocated keeps only the last group for each GroupBy/RuleType, so it
+// cannot tell apart allocated node groups that differ only by Node.
+func AllowedAndAllocatedForNode(status *api.ResourceControllerStatus, nodeName string) (ResourceControllerRuleTypeToResourceList, ResourceControllerRuleTypeToResourceList) {
+	allowed := make(ResourceControllerRuleTypeToResourceList)
+	allocated := make(ResourceControllerRuleTypeToResourceList)
+
+	for _, group := range status.Allowed {
+		if group.GroupBy == api.ResourceControllerGroupByNode {
+			allowed[group.RuleType] = group.Resources
+		}
+	}
+	for _, group := range status.Allocated {
+		if group.GroupBy == api.ResourceControllerGroupByNode && group.Node == nodeName {
+			allocated[group.RuleType] = group.Resources
+		}
+	}
+	return allowed, allocated
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+func podInNamespace(namespace, name, nodeName string) api.Pod {
+	pod := api.Pod{ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: name}}
+	pod.Spec.NodeName = nodeName
+	return pod
+}
+
+func informerWithPods(pods ...api.Pod) *namespaceInformer {
+	informer := newNamespaceInformer(nil, 0, nil)
+	for _, pod := range pods {
+		informer.podsByNamespace[pod.Namespace] = append(informer.podsByNamespace[pod.Namespace], pod)
+	}
+	return informer
+}
+
+// TestPodStoreForNamespace verifies the store an ObserverFunc receives is seeded from the
+// namespace informer's cache and scoped to the requested namespace, rather than starting empty.
+func TestPodStoreForNamespace(t *testing.T) {
+	rm := &ResourceManager{informer: informerWithPods(
+		podInNamespace("ns1", "a", "node1"),
+		podInNamespace("ns1", "b", "node2"),
+		podInNamespace("ns2", "c", "node1"),
+	)}
+
+	store := rm.podStoreForNamespace("ns1")
+	if got := len(store.List()); got != 2 {
+		t.Fatalf("expected 2 pods cached for ns1, got %d", got)
+	}
+	if got := len(rm.podStoreForNamespace("ns2").List()); got != 1 {
+		t.Errorf("expected 1 pod cached for ns2, got %d", got)
+	}
+	if got := len(rm.podStoreForNamespace("ns3").List()); got != 0 {
+		t.Errorf("expected 0 pods cached for an unknown namespace, got %d", got)
+	}
+}
+
+// TestPodStoreForNode verifies the per-node store observeNodeGroup uses is additionally scoped to
+// the pods scheduled onto the requested node.
+func TestPodStoreForNode(t *testing.T) {
+	rm := &ResourceManager{informer: informerWithPods(
+		podInNamespace("ns1", "a", "node1"),
+		podInNamespace("ns1", "b", "node2"),
+		podInNamespace("ns1", "c", "node1"),
+	)}
+
+	store := rm.podStoreForNode("ns1", "node1")
+	if got := len(store.List()); got != 2 {
+		t.Fatalf("expected 2 pods scheduled onto node1, got %d", got)
+	}
+	if got := len(rm.podStoreForNode("ns1", "node3").List()); got != 0 {
+		t.Errorf("expected 0 pods scheduled onto a node with none, got %d", got)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+// PodMatchesScopes reports whether pod satisfies every scope in scopes. An empty scopes always
+// matches, which keeps the zero-value ResourceController applying to every pod as it always has.
+func PodMatchesScopes(pod api.Pod, scopes []api.ResourceControllerScope) bool {
+	for _, scope := range scopes {
+		if !podMatchesScope(pod, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func podMatchesScope(pod api.Pod, scope api.ResourceControllerScope) bool {
+	switch scope {
+	case api.ResourceControllerScopeBestEffort:
+		return isBestEffort(pod)
+	case api.ResourceControllerScopeNotBestEffort:
+		return !isBestEffort(pod)
+	case api.ResourceControllerScopeTerminating:
+		return pod.Spec.ActiveDeadlineSeconds != nil
+	case api.ResourceControllerScopeNotTerminating:
+		return pod.Spec.ActiveDeadlineSeconds == nil
+	}
+	return true
+}
+
+// isBestEffort reports whether every container in pod requests zero CPU and zero Memory.
+func isBestEffort(pod api.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.CPU.MilliValue() != 0 || container.Memory.Value() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelsMatchSelector reports whether objectLabels satisfy selector. An empty selector always
+// matches, so objects are unaffected unless a ResourceController opts into narrowing by Selector.
+// This API generation carries Spec.Selector as a plain map[string]string (equality-only matching)
+// rather than a *api.LabelSelector, so there is no set-based (In/NotIn/Exists) matching to support
+// here; every admission func that checks Selector passes the same plain map through unchanged.
+func LabelsMatchSelector(objectLabels, selector map[string]string) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	return labels.Set(selector).AsSelector().Matches(labels.Set(objectLabels))
+}
+
+// PodMatchesController reports whether controller's Spec.Scopes and Spec.Selector both match pod,
+// i.e. whether controller's quota applies to pod at all.
+func PodMatchesController(controller api.ResourceController, pod api.Pod) bool {
+	return PodMatchesScopes(pod, controller.Spec.Scopes) && LabelsMatchSelector(pod.Labels, controller.Spec.Selector)
+}
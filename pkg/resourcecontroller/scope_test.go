@@ -0,0 +1,97 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcecontroller
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+)
+
+func podWithContainerResources(cpu, memory int64) api.Pod {
+	return api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{{
+				CPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				Memory: *resource.NewQuantity(memory, resource.DecimalSI),
+			}},
+		},
+	}
+}
+
+func TestIsBestEffort(t *testing.T) {
+	if !isBestEffort(podWithContainerResources(0, 0)) {
+		t.Errorf("expected a pod with no CPU/Memory requests to be best-effort")
+	}
+	if isBestEffort(podWithContainerResources(100, 0)) {
+		t.Errorf("expected a pod requesting CPU to not be best-effort")
+	}
+	if isBestEffort(podWithContainerResources(0, 1024)) {
+		t.Errorf("expected a pod requesting Memory to not be best-effort")
+	}
+}
+
+func TestPodMatchesScopes(t *testing.T) {
+	bestEffort := podWithContainerResources(0, 0)
+	guaranteed := podWithContainerResources(100, 1024)
+	terminating := guaranteed
+	deadline := int64(30)
+	terminating.Spec.ActiveDeadlineSeconds = &deadline
+
+	table := []struct {
+		name   string
+		pod    api.Pod
+		scopes []api.ResourceControllerScope
+		want   bool
+	}{
+		{"empty scopes always match", guaranteed, nil, true},
+		{"BestEffort matches a best-effort pod", bestEffort, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, true},
+		{"BestEffort rejects a guaranteed pod", guaranteed, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort}, false},
+		{"NotBestEffort matches a guaranteed pod", guaranteed, []api.ResourceControllerScope{api.ResourceControllerScopeNotBestEffort}, true},
+		{"Terminating matches a pod with a deadline", terminating, []api.ResourceControllerScope{api.ResourceControllerScopeTerminating}, true},
+		{"NotTerminating rejects a pod with a deadline", terminating, []api.ResourceControllerScope{api.ResourceControllerScopeNotTerminating}, false},
+		{"multiple scopes all must match", bestEffort, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort, api.ResourceControllerScopeNotTerminating}, true},
+		{"multiple scopes fail if any one fails", bestEffort, []api.ResourceControllerScope{api.ResourceControllerScopeBestEffort, api.ResourceControllerScopeTerminating}, false},
+	}
+
+	for _, item := range table {
+		if got := PodMatchesScopes(item.pod, item.scopes); got != item.want {
+			t.Errorf("%s: PodMatchesScopes() = %v, want %v", item.name, got, item.want)
+		}
+	}
+}
+
+func TestLabelsMatchSelector(t *testing.T) {
+	table := []struct {
+		name     string
+		labels   map[string]string
+		selector map[string]string
+		want     bool
+	}{
+		{"empty selector always matches", map[string]string{"env": "prod"}, nil, true},
+		{"matching selector", map[string]string{"env": "prod", "tier": "web"}, map[string]string{"env": "prod"}, true},
+		{"non-matching value", map[string]string{"env": "staging"}, map[string]string{"env": "prod"}, false},
+		{"missing key", map[string]string{"tier": "web"}, map[string]string{"env": "prod"}, false},
+	}
+
+	for _, item := range table {
+		if got := LabelsMatchSelector(item.labels, item.selector); got != item.want {
+			t.Errorf("%s: LabelsMatchSelector() = %v, want %v", item.name, got, item.want)
+		}
+	}
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package resourcelimits
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
@@ -26,17 +30,37 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/meta"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/golang/glog"
 )
 
 func init() {
 	admission.RegisterPlugin("ResourceLimits", func(client client.Interface, config io.Reader) (admission.Interface, error) {
-		return &limits{client: client, admissionFuncs: GetAdmissionFuncs()}, nil
+		return &limits{client: client, admissionFuncs: GetAdmissionFuncs(), eventSink: eventSinkFromConfig(config)}, nil
 	})
 }
 
+// eventSinkFromConfig builds the EventSink the plugin audits admission decisions to. config, if
+// non-empty, is the URL of an HTTP CloudEvents receiver; an empty or absent config disables auditing.
+func eventSinkFromConfig(config io.Reader) EventSink {
+	if config == nil {
+		return noopEventSink{}
+	}
+	data, err := ioutil.ReadAll(config)
+	if err != nil {
+		glog.Errorf("resourcelimits: unable to read EventSink config, admission decisions will not be audited: %v", err)
+		return noopEventSink{}
+	}
+	url := strings.TrimSpace(string(data))
+	if len(url) == 0 {
+		return noopEventSink{}
+	}
+	return newHTTPEventSink(url)
+}
+
 type limits struct {
 	client         client.Interface
 	admissionFuncs []AdmissionFunc
+	eventSink      EventSink
 }
 
 func (l *limits) Admit(a admission.Attributes) (err error) {
@@ -62,18 +86,39 @@ func (l *limits) Admit(a admission.Attributes) (err error) {
 			Status: api.ResourceControllerStatus{},
 		}
 		resourceObservation.Status.Allowed = make([]api.ResourceControllerGroup, len(controller.Status.Allowed), len(controller.Status.Allowed))
-		resourceObservation.Status.Allocated = make([]api.ResourceControllerGroup, len(controller.Status.Allowed), len(controller.Status.Allowed))
+		resourceObservation.Status.Allocated = make([]api.ResourceControllerGroup, len(controller.Status.Allocated), len(controller.Status.Allocated))
 		copy(resourceObservation.Status.Allowed, controller.Status.Allowed)
 		copy(resourceObservation.Status.Allocated, controller.Status.Allocated)
 
-		// invoke each registered admissionFunc
 		dirty := false
-		for _, admissionFunc := range l.admissionFuncs {
-			funcDirty, err := admissionFunc(a, &controller, &resourceObservation, l.client)
-			if err != nil {
-				return err
+
+		// PreAdmit hooks run before any admissionFunc; a failure here short-circuits the controller
+		// entirely, so no admissionFunc ever sees this request.
+		preDirty, admitErr := runHooks(PreAdmit, a, &controller, &resourceObservation, l.client)
+		dirty = dirty || preDirty
+
+		// invoke each registered admissionFunc
+		if admitErr == nil {
+			for _, admissionFunc := range l.admissionFuncs {
+				funcDirty, err := admissionFunc(a, &controller, &resourceObservation, l.client)
+				if err != nil {
+					admitErr = err
+					break
+				}
+				dirty = dirty || funcDirty
 			}
-			dirty = dirty || funcDirty
+		}
+
+		if admitErr == nil {
+			postDirty, err := runHooks(PostAdmit, a, &controller, &resourceObservation, l.client)
+			dirty = dirty || postDirty
+			admitErr = err
+		}
+
+		l.emitAdmissionEvent(a, &controller, &resourceObservation, admitErr)
+
+		if admitErr != nil {
+			return admitErr
 		}
 
 		if dirty {
@@ -81,7 +126,49 @@ func (l *limits) Admit(a admission.Attributes) (err error) {
 			if err != nil {
 				return apierrors.NewForbidden(a.GetKind(), name, fmt.Errorf("Unable to %s %s at this time because there was an error enforcing admission control", a.GetOperation(), a.GetKind()))
 			}
+
+			// PostPersist hooks run only after the observation above was durably persisted. Their
+			// errors are logged but never undo the persisted observation.
+			if _, err := runHooks(PostPersist, a, &controller, &resourceObservation, l.client); err != nil {
+				glog.Errorf("resourcelimits: PostPersist hook failed for %s/%s: %v", controller.Namespace, controller.Name, err)
+			}
 		}
 	}
 	return nil
 }
+
+// emitAdmissionEvent audits a single admission decision against controller to l.eventSink as a
+// CloudEvent. admitErr is the error (if any) returned by controller's admissionFuncs; a nil admitErr
+// emits an "allowed" event, otherwise a "denied" event. Emit failures are logged and otherwise
+// ignored, so a misbehaving or unreachable sink never blocks admission.
+func (l *limits) emitAdmissionEvent(a admission.Attributes, controller *api.ResourceController, observation *api.ResourceObservation, admitErr error) {
+	name := "Unknown"
+	if a.GetObject() != nil {
+		if accessorName, err := meta.NewAccessor().Name(a.GetObject()); err == nil {
+			name = accessorName
+		}
+	}
+
+	eventType := admissionEventTypeAllowed
+	if admitErr != nil {
+		eventType = admissionEventTypeDenied
+	}
+
+	event := CloudEvent{
+		SpecVersion:     cloudEventSpecVersion,
+		ID:              fmt.Sprintf("%s.%s.%d", controller.Namespace, controller.Name, time.Now().UnixNano()),
+		Source:          fmt.Sprintf("/apis/resourcecontroller/%s/%s", controller.Namespace, controller.Name),
+		Type:            eventType,
+		Subject:         fmt.Sprintf("%s/%s", a.GetKind(), name),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data: admissionEventData{
+			Operation: string(a.GetOperation()),
+			Rules:     admissionRuleObservations(&observation.Status),
+		},
+	}
+
+	if err := l.eventSink.Emit(context.Background(), event); err != nil {
+		glog.Errorf("resourcelimits: failed to emit admission %s event for %s/%s: %v", eventType, controller.Namespace, controller.Name, err)
+	}
+}
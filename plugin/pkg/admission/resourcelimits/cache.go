@@ -0,0 +1,242 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/golang/glog"
+)
+
+// defaultResyncPeriod is how often sharedInformerFactory refreshes its cluster-wide cache
+const defaultResyncPeriod = 30 * time.Second
+
+// sharedInformerFactory maintains a periodically refreshed, cluster-wide cache of the object kinds
+// the ResourceLimits observer needs, so that a busy master no longer pays for a fresh List call to
+// the apiserver on every reconcile of every ResourceController. It plays the same role here that a
+// client-go SharedInformerFactory plays for newer controllers, built against this repo's existing
+// client.Interface rather than a watch-based informer.
+type sharedInformerFactory struct {
+	client client.Interface
+	resync time.Duration
+
+	lock                               sync.RWMutex
+	podsByNamespace                    map[string][]api.Pod
+	servicesByNamespace                map[string][]api.Service
+	replicationControllersByNamespace  map[string][]api.ReplicationController
+	daemonSetsByNamespace              map[string][]api.DaemonSet
+	persistentVolumeClaimsByNamespace  map[string][]api.PersistentVolumeClaim
+	secretsByNamespace                 map[string][]api.Secret
+	configMapsByNamespace              map[string][]api.ConfigMap
+}
+
+// newSharedInformerFactory returns a factory that has not yet been started; call Run to populate it
+func newSharedInformerFactory(client client.Interface, resync time.Duration) *sharedInformerFactory {
+	return &sharedInformerFactory{
+		client:                            client,
+		resync:                            resync,
+		podsByNamespace:                   map[string][]api.Pod{},
+		servicesByNamespace:               map[string][]api.Service{},
+		replicationControllersByNamespace: map[string][]api.ReplicationController{},
+		daemonSetsByNamespace:             map[string][]api.DaemonSet{},
+		persistentVolumeClaimsByNamespace: map[string][]api.PersistentVolumeClaim{},
+		secretsByNamespace:                map[string][]api.Secret{},
+		configMapsByNamespace:             map[string][]api.ConfigMap{},
+	}
+}
+
+// Run performs an initial, synchronous refresh of every cached kind, so that callers that wait on
+// Run returning are guaranteed a warm cache, and then refreshes the cache every resync period until
+// stopCh is closed.
+func (f *sharedInformerFactory) Run(stopCh <-chan struct{}) {
+	f.refresh()
+
+	go func() {
+		ticker := time.Tick(f.resync)
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker:
+				f.refresh()
+			}
+		}
+	}()
+}
+
+// refresh lists every kind across all namespaces and re-indexes it by namespace
+func (f *sharedInformerFactory) refresh() {
+	pods, err := f.client.Pods(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing pods: %v", err)
+	} else {
+		byNamespace := map[string][]api.Pod{}
+		for _, pod := range pods.Items {
+			byNamespace[pod.Namespace] = append(byNamespace[pod.Namespace], pod)
+		}
+		f.lock.Lock()
+		f.podsByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	services, err := f.client.Services(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing services: %v", err)
+	} else {
+		byNamespace := map[string][]api.Service{}
+		for _, service := range services.Items {
+			byNamespace[service.Namespace] = append(byNamespace[service.Namespace], service)
+		}
+		f.lock.Lock()
+		f.servicesByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	replicationControllers, err := f.client.ReplicationControllers(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing replication controllers: %v", err)
+	} else {
+		byNamespace := map[string][]api.ReplicationController{}
+		for _, rc := range replicationControllers.Items {
+			byNamespace[rc.Namespace] = append(byNamespace[rc.Namespace], rc)
+		}
+		f.lock.Lock()
+		f.replicationControllersByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	daemonSets, err := f.client.DaemonSets(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing daemon sets: %v", err)
+	} else {
+		byNamespace := map[string][]api.DaemonSet{}
+		for _, daemonSet := range daemonSets.Items {
+			byNamespace[daemonSet.Namespace] = append(byNamespace[daemonSet.Namespace], daemonSet)
+		}
+		f.lock.Lock()
+		f.daemonSetsByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	persistentVolumeClaims, err := f.client.PersistentVolumeClaims(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing persistent volume claims: %v", err)
+	} else {
+		byNamespace := map[string][]api.PersistentVolumeClaim{}
+		for _, claim := range persistentVolumeClaims.Items {
+			byNamespace[claim.Namespace] = append(byNamespace[claim.Namespace], claim)
+		}
+		f.lock.Lock()
+		f.persistentVolumeClaimsByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	secrets, err := f.client.Secrets(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing secrets: %v", err)
+	} else {
+		byNamespace := map[string][]api.Secret{}
+		for _, secret := range secrets.Items {
+			byNamespace[secret.Namespace] = append(byNamespace[secret.Namespace], secret)
+		}
+		f.lock.Lock()
+		f.secretsByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+
+	configMaps, err := f.client.ConfigMaps(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("sharedInformerFactory: error listing config maps: %v", err)
+	} else {
+		byNamespace := map[string][]api.ConfigMap{}
+		for _, configMap := range configMaps.Items {
+			byNamespace[configMap.Namespace] = append(byNamespace[configMap.Namespace], configMap)
+		}
+		f.lock.Lock()
+		f.configMapsByNamespace = byNamespace
+		f.lock.Unlock()
+	}
+}
+
+// Pods lists the cached pods in namespace, mirroring corelisters.PodLister.Pods(ns).List(...)
+func (f *sharedInformerFactory) Pods(namespace string) []api.Pod {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.podsByNamespace[namespace]
+}
+
+// PodsForNode lists every cached pod, across all namespaces, bound to nodeName.
+func (f *sharedInformerFactory) PodsForNode(nodeName string) []api.Pod {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	var pods []api.Pod
+	for _, namespacePods := range f.podsByNamespace {
+		for _, pod := range namespacePods {
+			if pod.Spec.NodeName == nodeName {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods
+}
+
+// Services lists the cached services in namespace
+func (f *sharedInformerFactory) Services(namespace string) []api.Service {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.servicesByNamespace[namespace]
+}
+
+// ReplicationControllers lists the cached replication controllers in namespace
+func (f *sharedInformerFactory) ReplicationControllers(namespace string) []api.ReplicationController {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.replicationControllersByNamespace[namespace]
+}
+
+// DaemonSets lists the cached daemon sets in namespace
+func (f *sharedInformerFactory) DaemonSets(namespace string) []api.DaemonSet {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.daemonSetsByNamespace[namespace]
+}
+
+// PersistentVolumeClaims lists the cached persistent volume claims in namespace
+func (f *sharedInformerFactory) PersistentVolumeClaims(namespace string) []api.PersistentVolumeClaim {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.persistentVolumeClaimsByNamespace[namespace]
+}
+
+// Secrets lists the cached secrets in namespace
+func (f *sharedInformerFactory) Secrets(namespace string) []api.Secret {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.secretsByNamespace[namespace]
+}
+
+// ConfigMaps lists the cached config maps in namespace
+func (f *sharedInformerFactory) ConfigMaps(namespace string) []api.ConfigMap {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.configMapsByNamespace[namespace]
+}
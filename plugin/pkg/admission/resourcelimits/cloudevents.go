@@ -0,0 +1,147 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+)
+
+const (
+	cloudEventSpecVersion = "1.0"
+
+	// admissionEventTypeAllowed and admissionEventTypeDenied are the CloudEvents 1.0 "type" attribute
+	// emitted for every ResourceLimits admission decision.
+	admissionEventTypeAllowed = "io.k8s.resourcecontroller.admission.allowed"
+	admissionEventTypeDenied  = "io.k8s.resourcecontroller.admission.denied"
+)
+
+// CloudEvent is the minimal CloudEvents 1.0 envelope ResourceLimits emits on every admission decision.
+type CloudEvent struct {
+	SpecVersion     string              `json:"specversion"`
+	ID              string              `json:"id"`
+	Source          string              `json:"source"`
+	Type            string              `json:"type"`
+	Subject         string              `json:"subject"`
+	Time            string              `json:"time"`
+	DataContentType string              `json:"datacontenttype"`
+	Data            admissionEventData  `json:"data"`
+}
+
+// admissionEventData is the CloudEvent "data" payload: the admitted operation, plus the resource
+// rules observed while making the decision.
+type admissionEventData struct {
+	Operation string             `json:"operation"`
+	Rules     []admissionRuleObservation `json:"rules"`
+}
+
+// admissionRuleObservation reports the allowed and allocated quantities ResourceLimits observed for
+// a single GroupBy/RuleType/ResourceName rule while admitting a request.
+type admissionRuleObservation struct {
+	GroupBy      api.ResourceControllerGroupBy   `json:"groupBy"`
+	RuleType     api.ResourceControllerRuleType  `json:"ruleType"`
+	ResourceName api.ResourceName                `json:"resourceName"`
+	Allowed      string                          `json:"allowed,omitempty"`
+	Allocated    string                          `json:"allocated,omitempty"`
+}
+
+// EventSink emits a CloudEvent produced by the ResourceLimits plugin. Emit failures are logged by the
+// caller and never block admission, so implementations need not retry internally.
+type EventSink interface {
+	Emit(ctx context.Context, event CloudEvent) error
+}
+
+// noopEventSink discards every event. It is the default sink, and is useful in tests that don't care
+// about the emitted audit trail.
+type noopEventSink struct{}
+
+func (noopEventSink) Emit(ctx context.Context, event CloudEvent) error { return nil }
+
+// httpEventSink POSTs each event to a configured URL using the CloudEvents HTTP binary content mode:
+// envelope attributes are carried as Ce-* headers, and the body is just the data payload.
+type httpEventSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func newHTTPEventSink(url string) *httpEventSink {
+	return &httpEventSink{url: url, httpClient: http.DefaultClient}
+}
+
+func (s *httpEventSink) Emit(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", event.DataContentType)
+	req.Header.Set("Ce-Specversion", event.SpecVersion)
+	req.Header.Set("Ce-Id", event.ID)
+	req.Header.Set("Ce-Source", event.Source)
+	req.Header.Set("Ce-Type", event.Type)
+	req.Header.Set("Ce-Subject", event.Subject)
+	req.Header.Set("Ce-Time", event.Time)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("event sink %v responded with status %v", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// admissionRuleObservations flattens status into one admissionRuleObservation per rule that declares
+// an allowed quantity, pairing it with the allocated quantity observed for the same rule, if any.
+func admissionRuleObservations(status *api.ResourceControllerStatus) []admissionRuleObservation {
+	allowedByGroup, allocatedByGroup := resourcecontroller.AllowedAndAllocated(status)
+
+	observations := []admissionRuleObservation{}
+	for groupBy, allowedByRule := range allowedByGroup {
+		allocatedByRule := allocatedByGroup[groupBy]
+		for ruleType, allowedResources := range allowedByRule {
+			allocatedResources := allocatedByRule[ruleType]
+			for resourceName, allowedQuantity := range allowedResources {
+				observation := admissionRuleObservation{
+					GroupBy:      groupBy,
+					RuleType:     ruleType,
+					ResourceName: resourceName,
+					Allowed:      allowedQuantity.String(),
+				}
+				if allocatedQuantity, found := allocatedResources[resourceName]; found {
+					observation.Allocated = allocatedQuantity.String()
+				}
+				observations = append(observations, observation)
+			}
+		}
+	}
+	return observations
+}
@@ -32,22 +32,98 @@ func init() {
 }
 
 func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	dirty := false
+
 	if a.GetOperation() == "DELETE" {
-		return false, nil
+		return dirty, nil
 	}
 
 	if a.GetKind() != "pods" {
-		return false, nil
+		return dirty, nil
 	}
 
 	allowedByGroup, _ := resourcecontroller.AllowedAndAllocated(&input.Status)
 	groupRules := allowedByGroup[api.ResourceControllerGroupByContainer]
 	if groupRules == nil {
-		return false, nil
+		return dirty, nil
 	}
 
 	obj := a.GetObject()
 	pod := obj.(*api.Pod)
+
+	if !resourcecontroller.PodMatchesController(*input, *pod) {
+		return dirty, nil
+	}
+
+	// RuleTypeDefault runs first and mutates the pod, so later Ratio/Max/Min checks below see the
+	// defaulted values rather than the zero value of an omitted CPU/Memory quantity.
+	for name, quantity := range groupRules[api.ResourceControllerRuleTypeDefault] {
+		for i := range pod.Spec.Containers {
+			container := &pod.Spec.Containers[i]
+			switch name {
+			case "CPU":
+				if container.CPU.IsZero() {
+					container.CPU = quantity
+					dirty = true
+				}
+			case "Memory":
+				if container.Memory.IsZero() {
+					container.Memory = quantity
+					dirty = true
+				}
+			}
+		}
+	}
+
+	// RuleTypeRatio caps a container's CPU/Memory quantity to at most a configured fraction of the
+	// same group's RuleTypeMax ceiling for that resource (see ResourceControllerRuleTypeRatio's doc
+	// comment for why this is expressed against Max rather than a limit-to-request ratio). A group
+	// with no configured Max for a resource has nothing for Ratio to be a fraction of, so that
+	// resource is skipped rather than treated as an unbounded allowance.
+	maxRules := groupRules[api.ResourceControllerRuleTypeMax]
+	for name, quantity := range groupRules[api.ResourceControllerRuleTypeRatio] {
+		maxQuantity, hasMax := maxRules[name]
+		if !hasMax {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			switch name {
+			case "CPU":
+				if maxQuantity.MilliValue() == 0 {
+					continue
+				}
+				allowedMilli := maxQuantity.MilliValue() * quantity.MilliValue() / 1000
+				if container.CPU.MilliValue() > allowedMilli {
+					return dirty, apierrors.NewForbidden(
+						a.GetKind(),
+						pod.Name,
+						fmt.Errorf("Unable to %v pod, container %v cpu %v is more than %vx the group's max %v",
+							a.GetOperation(),
+							container.Name,
+							container.CPU.String(),
+							float64(quantity.MilliValue())/1000,
+							maxQuantity.String()))
+				}
+			case "Memory":
+				if maxQuantity.Value() == 0 {
+					continue
+				}
+				allowedMilli := maxQuantity.MilliValue() * quantity.MilliValue() / 1000
+				if container.Memory.MilliValue() > allowedMilli {
+					return dirty, apierrors.NewForbidden(
+						a.GetKind(),
+						pod.Name,
+						fmt.Errorf("Unable to %v pod, container %v memory %v is more than %vx the group's max %v",
+							a.GetOperation(),
+							container.Name,
+							container.Memory.String(),
+							float64(quantity.MilliValue())/1000,
+							maxQuantity.String()))
+				}
+			}
+		}
+	}
+
 	for ruleType, resources := range groupRules {
 		for name, quantity := range resources {
 			for _, container := range pod.Spec.Containers {
@@ -56,7 +132,7 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 					switch name {
 					case "Memory":
 						if container.Memory.Value() > quantity.Value() {
-							return false, apierrors.NewForbidden(
+							return dirty, apierrors.NewForbidden(
 								a.GetKind(),
 								pod.Name,
 								fmt.Errorf("Unable to %v pod, container %v requests %v memory that is greater than the max: %v",
@@ -67,7 +143,7 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 						}
 					case "CPU":
 						if container.CPU.MilliValue() > quantity.MilliValue() {
-							return false, apierrors.NewForbidden(
+							return dirty, apierrors.NewForbidden(
 								a.GetKind(),
 								pod.Name,
 								fmt.Errorf("Unable to %v pod, container %v requests %v cpu that is greater than the max: %v",
@@ -81,7 +157,7 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 					switch name {
 					case "CPU":
 						if container.CPU.MilliValue() < quantity.MilliValue() {
-							return false, apierrors.NewForbidden(
+							return dirty, apierrors.NewForbidden(
 								a.GetKind(),
 								pod.Name,
 								fmt.Errorf("Unable to %v pod, container %v requests %v cpu that is less than the min: %v",
@@ -92,7 +168,7 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 						}
 					case "Memory":
 						if container.Memory.Value() < quantity.Value() {
-							return false, apierrors.NewForbidden(
+							return dirty, apierrors.NewForbidden(
 								a.GetKind(),
 								pod.Name,
 								fmt.Errorf("Unable to %v pod, container %v requests %v memory that is less than the min: %v",
@@ -106,5 +182,5 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 			}
 		}
 	}
-	return false, nil
+	return dirty, nil
 }
@@ -0,0 +1,132 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving admissionFunc in tests.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string      { return f.namespace }
+func (f fakeAttributes) GetKind() string           { return f.kind }
+func (f fakeAttributes) GetOperation() string      { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+func newPodAttributes(pod *api.Pod) admission.Attributes {
+	return fakeAttributes{
+		namespace: pod.Namespace,
+		kind:      "pods",
+		operation: "CREATE",
+		object:    pod,
+	}
+}
+
+func controllerWithGroupRules(resources ...api.ResourceControllerGroup) *api.ResourceController {
+	return &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: resources,
+		},
+	}
+}
+
+// TestAdmissionFuncDefaultsMissingRequests verifies RuleTypeDefault mutates a container's zero
+// CPU/Memory quantities and reports dirty so the admission chain re-persists the defaulted pod.
+func TestAdmissionFuncDefaultsMissingRequests(t *testing.T) {
+	input := controllerWithGroupRules(api.ResourceControllerGroup{
+		GroupBy:  api.ResourceControllerGroupByContainer,
+		RuleType: api.ResourceControllerRuleTypeDefault,
+		Resources: api.ResourceList{
+			"CPU":    *resource.NewMilliQuantity(100, resource.DecimalSI),
+			"Memory": *resource.NewQuantity(1024, resource.DecimalSI),
+		},
+	})
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "test-pod"},
+		Spec: api.PodSpec{
+			Containers: []api.Container{{Name: "c"}},
+		},
+	}
+
+	dirty, err := admissionFunc(newPodAttributes(pod), input, &api.ResourceObservation{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dirty {
+		t.Errorf("expected dirty to be true once a container's CPU/Memory was defaulted")
+	}
+	if got := pod.Spec.Containers[0].CPU.MilliValue(); got != 100 {
+		t.Errorf("CPU = %v, want 100m", got)
+	}
+	if got := pod.Spec.Containers[0].Memory.Value(); got != 1024 {
+		t.Errorf("Memory = %v, want 1024", got)
+	}
+}
+
+// TestAdmissionFuncRatioAgainstMax verifies RuleTypeRatio rejects a container whose CPU exceeds
+// the configured fraction of the group's RuleTypeMax ceiling, and allows one that stays within it.
+func TestAdmissionFuncRatioAgainstMax(t *testing.T) {
+	input := controllerWithGroupRules(
+		api.ResourceControllerGroup{
+			GroupBy:  api.ResourceControllerGroupByContainer,
+			RuleType: api.ResourceControllerRuleTypeMax,
+			Resources: api.ResourceList{
+				"CPU": *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			},
+		},
+		api.ResourceControllerGroup{
+			GroupBy:  api.ResourceControllerGroupByContainer,
+			RuleType: api.ResourceControllerRuleTypeRatio,
+			Resources: api.ResourceList{
+				// 500m means "at most half of the group's Max".
+				"CPU": *resource.NewMilliQuantity(500, resource.DecimalSI),
+			},
+		},
+	)
+
+	newPod := func(cpuMilli int64) *api.Pod {
+		return &api.Pod{
+			ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "test-pod"},
+			Spec: api.PodSpec{
+				Containers: []api.Container{{
+					Name: "c",
+					CPU:  *resource.NewMilliQuantity(cpuMilli, resource.DecimalSI),
+				}},
+			},
+		}
+	}
+
+	if _, err := admissionFunc(newPodAttributes(newPod(400)), input, &api.ResourceObservation{}, nil); err != nil {
+		t.Errorf("unexpected error admitting a container within the ratio: %v", err)
+	}
+
+	if _, err := admissionFunc(newPodAttributes(newPod(600)), input, &api.ResourceObservation{}, nil); err == nil {
+		t.Errorf("expected an error admitting a container over half of the group's max, got none")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/admission/resourcelimits"
+)
+
+func init() {
+	resourcelimits.RegisterAdmissionFunc("ResourceLimitsDaemonSet", admissionFunc)
+}
+
+func makeObservation(status *api.ResourceControllerStatus, newQuantity *resource.Quantity) {
+	_, observedAllocatedByGroup := resourcecontroller.AllowedAndAllocated(status)
+	observedAllocatedGroupRules := observedAllocatedByGroup[api.ResourceControllerGroupByDaemonSet]
+	observedAllocatedGroupRulesMax := observedAllocatedGroupRules[api.ResourceControllerRuleTypeMax]
+	observedAllocatedGroupRulesMax["DaemonSets"] = *newQuantity
+}
+
+func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	dirty := false
+
+	if a.GetOperation() != "CREATE" {
+		return dirty, nil
+	}
+
+	if a.GetKind() != "daemonSets" {
+		return dirty, nil
+	}
+
+	allowedByGroup, allocatedByGroup := resourcecontroller.AllowedAndAllocated(&input.Status)
+	allowedGroupRules := allowedByGroup[api.ResourceControllerGroupByDaemonSet]
+	if allowedGroupRules == nil {
+		return dirty, nil
+	}
+	allowedMax, exists := allowedGroupRules[api.ResourceControllerRuleTypeMax]["DaemonSets"]
+	if !exists {
+		return dirty, nil
+	}
+
+	daemonSet := a.GetObject().(*api.DaemonSet)
+
+	if !resourcecontroller.LabelsMatchSelector(daemonSet.Labels, input.Spec.Selector) {
+		return dirty, nil
+	}
+
+	allocatedMax, observationExists := allocatedByGroup[api.ResourceControllerGroupByDaemonSet][api.ResourceControllerRuleTypeMax]["DaemonSets"]
+	if !observationExists {
+		return dirty, apierrors.NewForbidden(a.GetKind(), daemonSet.Name, fmt.Errorf("Unable to admit resource, waiting for resource observation to complete."))
+	}
+
+	if allocatedMax.Value() >= allowedMax.Value() {
+		return dirty, apierrors.NewForbidden(a.GetKind(), daemonSet.Name,
+			fmt.Errorf("Limited to %v daemon sets in namespace %v", allowedMax.String(), input.Namespace))
+	}
+
+	makeObservation(&observation.Status, resource.NewQuantity(allocatedMax.Value()+1, resource.DecimalSI))
+	dirty = true
+	return dirty, nil
+}
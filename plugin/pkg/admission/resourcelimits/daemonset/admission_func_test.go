@@ -0,0 +1,111 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving admissionFunc in tests.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string      { return f.namespace }
+func (f fakeAttributes) GetKind() string           { return f.kind }
+func (f fakeAttributes) GetOperation() string      { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+func newDaemonSetAttributes(daemonSet *api.DaemonSet) admission.Attributes {
+	return fakeAttributes{
+		namespace: daemonSet.Namespace,
+		kind:      "daemonSets",
+		operation: "CREATE",
+		object:    daemonSet,
+	}
+}
+
+func controllerWithDaemonSetMax(max, allocated int64, selector map[string]string) *api.ResourceController {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByDaemonSet,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"DaemonSets": *resource.NewQuantity(max, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByDaemonSet,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"DaemonSets": *resource.NewQuantity(allocated, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+	ctrl.Spec.Selector = selector
+	return ctrl
+}
+
+// TestAdmissionFunc verifies admissionFunc only charges a DaemonSet against the quota when it's
+// selected by the controller's spec.Selector, and is a no-op for one that isn't.
+func TestAdmissionFunc(t *testing.T) {
+	table := []struct {
+		name      string
+		selector  map[string]string
+		labels    map[string]string
+		wantErr   bool
+		wantDirty bool
+	}{
+		{"no selector matches everything, room left", nil, nil, false, true},
+		{"matching selector, room left", map[string]string{"app": "web"}, map[string]string{"app": "web"}, false, true},
+		{"non-matching selector is skipped entirely", map[string]string{"app": "web"}, map[string]string{"app": "db"}, false, false},
+	}
+
+	for _, item := range table {
+		input := controllerWithDaemonSetMax(5, 4, item.selector)
+		daemonSet := &api.DaemonSet{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "ds", Labels: item.labels}}
+
+		dirty, err := admissionFunc(newDaemonSetAttributes(daemonSet), input, &api.ResourceObservation{Status: input.Status}, nil)
+		if (err != nil) != item.wantErr {
+			t.Errorf("%s: admissionFunc() error = %v, wantErr %v", item.name, err, item.wantErr)
+		}
+		if dirty != item.wantDirty {
+			t.Errorf("%s: admissionFunc() dirty = %v, want %v", item.name, dirty, item.wantDirty)
+		}
+	}
+}
+
+// TestAdmissionFuncDeniesAtMax verifies admissionFunc rejects a DaemonSet once the namespace is
+// already at its allowed max.
+func TestAdmissionFuncDeniesAtMax(t *testing.T) {
+	input := controllerWithDaemonSetMax(1, 1, nil)
+	daemonSet := &api.DaemonSet{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "ds"}}
+
+	if _, err := admissionFunc(newDaemonSetAttributes(daemonSet), input, &api.ResourceObservation{}, nil); err == nil {
+		t.Errorf("expected an error admitting a DaemonSet once the namespace is already at its max")
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/golang/glog"
+)
+
+// HookPhase names a point in limits.Admit's per-controller admission pipeline a Hook can run at.
+type HookPhase string
+
+const (
+	// PreAdmit hooks run before the built-in admissionFuncs, once per ResourceController being
+	// considered. A PreAdmit hook that returns an error short-circuits the controller: no
+	// admissionFunc runs and the error is returned to the caller of Admit.
+	PreAdmit HookPhase = "PreAdmit"
+	// PostAdmit hooks run after the built-in admissionFuncs have all succeeded, before the resulting
+	// ResourceObservation (if dirty) is persisted.
+	PostAdmit HookPhase = "PostAdmit"
+	// PostPersist hooks run after a dirty ResourceObservation has been successfully created. A
+	// PostPersist hook error is logged but never undoes the persisted observation.
+	PostPersist HookPhase = "PostPersist"
+)
+
+// Hook is a pluggable extension to limits.Admit's decision pipeline, modeled on Helm's pre/post
+// install hooks: a named, weighted unit of work that runs at a fixed phase of the pipeline and can
+// mutate the in-flight ResourceObservation, e.g. to annotate it with the rule that tripped it.
+type Hook interface {
+	// Name identifies the hook for logging and duplicate-registration detection.
+	Name() string
+	// Weight orders hooks within a phase; hooks run in ascending Weight order, breaking ties by Name,
+	// matching the ordering Helm uses to sort same-phase hooks.
+	Weight() int
+	// Phase reports which phase of the pipeline this hook runs in.
+	Phase() HookPhase
+	// Run executes the hook. dirty reports whether obs was mutated and should be persisted.
+	Run(a admission.Attributes, controller *api.ResourceController, obs *api.ResourceObservation, client client.Interface) (dirty bool, err error)
+}
+
+var hooksMutex sync.Mutex
+var hooks = []Hook{}
+
+// RegisterHook registers hook to run at its declared Phase during every limits.Admit call.
+func RegisterHook(hook Hook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	for _, existing := range hooks {
+		if existing.Name() == hook.Name() {
+			glog.Fatalf("resourcelimits: hook with name %q was registered twice", hook.Name())
+		}
+	}
+
+	glog.V(1).Infof("resourcelimits: registered hook %q for phase %v at weight %d", hook.Name(), hook.Phase(), hook.Weight())
+	hooks = append(hooks, hook)
+}
+
+// hooksForPhase returns the hooks registered for phase, ordered by ascending weight and then name.
+func hooksForPhase(phase HookPhase) []Hook {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	matched := make([]Hook, 0, len(hooks))
+	for _, hook := range hooks {
+		if hook.Phase() == phase {
+			matched = append(matched, hook)
+		}
+	}
+	sort.Sort(byHookWeightThenName(matched))
+	return matched
+}
+
+// runHooks runs every hook registered for phase, in order, against the same controller/obs the
+// built-in admissionFuncs are run against. It stops at the first error, returning whether any hook
+// run so far marked obs dirty.
+func runHooks(phase HookPhase, a admission.Attributes, controller *api.ResourceController, obs *api.ResourceObservation, client client.Interface) (dirty bool, err error) {
+	for _, hook := range hooksForPhase(phase) {
+		hookDirty, err := hook.Run(a, controller, obs, client)
+		dirty = dirty || hookDirty
+		if err != nil {
+			return dirty, err
+		}
+	}
+	return dirty, nil
+}
+
+// byHookWeightThenName sorts Hooks by ascending Weight, breaking ties by Name.
+type byHookWeightThenName []Hook
+
+func (b byHookWeightThenName) Len() int      { return len(b) }
+func (b byHookWeightThenName) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byHookWeightThenName) Less(i, j int) bool {
+	if b[i].Weight() != b[j].Weight() {
+		return b[i].Weight() < b[j].Weight()
+	}
+	return b[i].Name() < b[j].Name()
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes usable by tests that need to drive the Hook
+// pipeline without a real apiserver request.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string  { return f.namespace }
+func (f fakeAttributes) GetKind() string       { return f.kind }
+func (f fakeAttributes) GetOperation() string  { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+// recordingHook is a Hook whose Run is scripted by the test; it records every controller it was
+// invoked against, in call order.
+type recordingHook struct {
+	name   string
+	weight int
+	phase  HookPhase
+	calls  *[]string
+	dirty  bool
+	err    error
+}
+
+func (h recordingHook) Name() string     { return h.name }
+func (h recordingHook) Weight() int      { return h.weight }
+func (h recordingHook) Phase() HookPhase { return h.phase }
+func (h recordingHook) Run(a admission.Attributes, controller *api.ResourceController, obs *api.ResourceObservation, client client.Interface) (bool, error) {
+	*h.calls = append(*h.calls, h.name)
+	return h.dirty, h.err
+}
+
+// TestHooksForPhaseOrdersByWeightThenName verifies RegisterHook/hooksForPhase reproduce Helm's
+// same-phase hook ordering: ascending weight, ties broken by name.
+func TestHooksForPhaseOrdersByWeightThenName(t *testing.T) {
+	var calls []string
+	resetHooksForTest()
+
+	RegisterHook(recordingHook{name: "z-second", weight: 5, phase: PreAdmit, calls: &calls})
+	RegisterHook(recordingHook{name: "a-first", weight: 5, phase: PreAdmit, calls: &calls})
+	RegisterHook(recordingHook{name: "runs-earliest", weight: -1, phase: PreAdmit, calls: &calls})
+	RegisterHook(recordingHook{name: "wrong-phase", weight: -100, phase: PostAdmit, calls: &calls})
+
+	controller := &api.ResourceController{}
+	obs := &api.ResourceObservation{}
+	if _, err := runHooks(PreAdmit, fakeAttributes{}, controller, obs, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"runs-earliest", "a-first", "z-second"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+// TestFailingPreAdmitHookShortCircuits verifies runHooks stops at a failing PreAdmit hook, so no
+// later PreAdmit hook runs.
+func TestFailingPreAdmitHookShortCircuits(t *testing.T) {
+	var calls []string
+	resetHooksForTest()
+
+	RegisterHook(recordingHook{name: "fails", weight: 0, phase: PreAdmit, calls: &calls, err: fmt.Errorf("denied by hook")})
+	RegisterHook(recordingHook{name: "never-runs", weight: 1, phase: PreAdmit, calls: &calls})
+
+	controller := &api.ResourceController{}
+	obs := &api.ResourceObservation{}
+	_, err := runHooks(PreAdmit, fakeAttributes{}, controller, obs, nil)
+	if err == nil {
+		t.Fatalf("expected an error from the failing hook")
+	}
+	if len(calls) != 1 || calls[0] != "fails" {
+		t.Errorf("calls = %v, want only [fails]; later PreAdmit hooks must not run once one fails", calls)
+	}
+}
+
+func resetHooksForTest() {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	hooks = []Hook{}
+}
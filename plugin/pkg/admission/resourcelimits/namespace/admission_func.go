@@ -56,6 +56,24 @@ func makeObservation(status *api.ResourceControllerStatus, resourceName api.Reso
 	observedAllocatedGroupRulesMax[resourceName] = *newQuantity
 }
 
+// makeNodeObservation records an aggregate observation for the given node and rule type, creating the
+// node-scoped group entry in the observation status the first time a resource is recorded for that node
+func makeNodeObservation(status *api.ResourceControllerStatus, nodeName string, ruleType api.ResourceControllerRuleType, resourceName api.ResourceName, newQuantity *resource.Quantity) {
+	for i := range status.Allocated {
+		group := &status.Allocated[i]
+		if group.GroupBy == api.ResourceControllerGroupByNode && group.Node == nodeName && group.RuleType == ruleType {
+			group.Resources[resourceName] = *newQuantity
+			return
+		}
+	}
+	status.Allocated = append(status.Allocated, api.ResourceControllerGroup{
+		GroupBy:   api.ResourceControllerGroupByNode,
+		RuleType:  ruleType,
+		Node:      nodeName,
+		Resources: api.ResourceList{resourceName: *newQuantity},
+	})
+}
+
 func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
 	groupBy := api.ResourceControllerGroupByNamespace
 	dirty := false
@@ -121,15 +139,16 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 
 			obj := a.GetObject()
 			pod := obj.(*api.Pod)
-			// compute local usage to this pod
-			if cpuExists || memExists {
-				podCPU := int64(0)
-				podMem := int64(0)
-				for _, container := range pod.Spec.Containers {
-					podCPU = podCPU + container.CPU.MilliValue()
-					podMem = podMem + container.Memory.Value()
-				}
 
+			// compute local usage to this pod once, it is shared by the namespace and node checks below
+			podCPU := int64(0)
+			podMem := int64(0)
+			for _, container := range pod.Spec.Containers {
+				podCPU = podCPU + container.CPU.MilliValue()
+				podMem = podMem + container.Memory.Value()
+			}
+
+			if cpuExists || memExists {
 				if cpuExists {
 					cpuObservation, cpuObservationExists := allocatedGroupRulesMax["CPU"]
 					if !cpuObservationExists {
@@ -165,6 +184,42 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 					}
 				}
 			}
+
+			// pods already bound to a node (e.g. static pods, or a Binding performed ahead of admission)
+			// are also checked against a per-node allocatable-request and overcommit-limit bound.
+			// This API generation models a single CPU/Memory quantity per container rather than
+			// separate request/limit quantities, so both rule types currently tally the same
+			// per-container value; once containers carry separate request/limit fields the two
+			// sums below should source from those fields independently.
+			if pod.Spec.NodeName != "" {
+				nodeAllowed, nodeAllocated := resourcecontroller.AllowedAndAllocatedForNode(&input.Status, pod.Spec.NodeName)
+
+				for _, ruleType := range []api.ResourceControllerRuleType{api.ResourceControllerRuleTypeRequest, api.ResourceControllerRuleTypeMax} {
+					allowed, allowedExists := nodeAllowed[ruleType]
+					if !allowedExists {
+						continue
+					}
+					allocated := nodeAllocated[ruleType]
+
+					if nodeCPULimit, exists := allowed["CPU"]; exists {
+						nodeCPUAllocated := allocated["CPU"]
+						if nodeCPUAllocated.MilliValue()+podCPU >= nodeCPULimit.MilliValue() {
+							return dirty, apierrors.NewForbidden(a.GetKind(), name, fmt.Errorf("Limited to %v CPU (%v) on node %v", nodeCPULimit.String(), ruleType, pod.Spec.NodeName))
+						}
+						makeNodeObservation(&observation.Status, pod.Spec.NodeName, ruleType, "CPU", resource.NewMilliQuantity(nodeCPUAllocated.MilliValue()+podCPU, resource.DecimalSI))
+						dirty = true
+					}
+
+					if nodeMemLimit, exists := allowed["Memory"]; exists {
+						nodeMemAllocated := allocated["Memory"]
+						if nodeMemAllocated.Value()+podMem >= nodeMemLimit.Value() {
+							return dirty, apierrors.NewForbidden(a.GetKind(), name, fmt.Errorf("Limited to %v memory (%v) on node %v", nodeMemLimit.String(), ruleType, pod.Spec.NodeName))
+						}
+						makeNodeObservation(&observation.Status, pod.Spec.NodeName, ruleType, "Memory", resource.NewQuantity(nodeMemAllocated.Value()+podMem, resource.DecimalSI))
+						dirty = true
+					}
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,127 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node admits a pod being bound to a node only if doing so would not push the node's
+// already-scheduled pods' CPU/Memory requests past the node's own Status.Allocatable. Unlike the
+// ResourceLimits* AdmissionFuncs registered elsewhere in resourcelimits, this plugin doesn't
+// depend on any ResourceController declaring a node-scoped Allowed group: it enforces "no node
+// oversubscribed" cluster-wide, independent of the scheduler and of whether a namespace opted into
+// quota at all.
+package node
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+func init() {
+	admission.RegisterPlugin("ResourceLimitsNode", func(client client.Interface, config io.Reader) (admission.Interface, error) {
+		return &nodeAllocatable{client: client}, nil
+	})
+}
+
+// nodeAllocatable denies admitting a pod to a node once the node's already-scheduled pods' CPU or
+// Memory requests, plus the incoming pod's own, would exceed the node's Status.Allocatable.
+type nodeAllocatable struct {
+	client client.Interface
+}
+
+func (n *nodeAllocatable) Admit(a admission.Attributes) error {
+	if a.GetOperation() != "CREATE" {
+		return nil
+	}
+
+	pod, nodeName, err := n.podAndNodeName(a)
+	if err != nil || pod == nil || nodeName == "" {
+		return nil
+	}
+
+	node, err := n.client.Nodes().Get(nodeName)
+	if err != nil {
+		// a pod naming a node that can't be found is not this plugin's concern to reject
+		return nil
+	}
+
+	cpuAllocatable, hasCPU := node.Status.Allocatable["CPU"]
+	memAllocatable, hasMemory := node.Status.Allocatable["Memory"]
+	if !hasCPU && !hasMemory {
+		return nil
+	}
+
+	podList, err := n.client.Pods(api.NamespaceAll).List(labels.Everything())
+	if err != nil {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("unable to admit pod %s to node %s at this time because node allocatable could not be checked: %v", pod.Name, nodeName, err))
+	}
+
+	var cpuUsed, memUsed int64
+	for _, scheduled := range podList.Items {
+		if scheduled.Spec.NodeName != nodeName || (scheduled.Namespace == pod.Namespace && scheduled.Name == pod.Name) {
+			continue
+		}
+		for _, container := range scheduled.Spec.Containers {
+			cpuUsed += container.CPU.MilliValue()
+			memUsed += container.Memory.Value()
+		}
+	}
+
+	var podCPU, podMem int64
+	for _, container := range pod.Spec.Containers {
+		podCPU += container.CPU.MilliValue()
+		podMem += container.Memory.Value()
+	}
+
+	if hasCPU && cpuUsed+podCPU > cpuAllocatable.MilliValue() {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("pod requests %s CPU, which would exceed node %q's allocatable %s (%s already requested by other pods)",
+			resource.NewMilliQuantity(podCPU, resource.DecimalSI).String(), nodeName, cpuAllocatable.String(), resource.NewMilliQuantity(cpuUsed, resource.DecimalSI).String()))
+	}
+	if hasMemory && memUsed+podMem > memAllocatable.Value() {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("pod requests %s memory, which would exceed node %q's allocatable %s (%s already requested by other pods)",
+			resource.NewQuantity(podMem, resource.DecimalSI).String(), nodeName, memAllocatable.String(), resource.NewQuantity(memUsed, resource.DecimalSI).String()))
+	}
+	return nil
+}
+
+// podAndNodeName extracts the pod and its target node name from either a Create of a Pod that
+// already names its node (e.g. a static/mirror pod), or a Create of a Binding (the scheduler's
+// normal path, which POSTs a Binding rather than updating the Pod directly).
+func (n *nodeAllocatable) podAndNodeName(a admission.Attributes) (*api.Pod, string, error) {
+	switch a.GetKind() {
+	case "pods":
+		pod, ok := a.GetObject().(*api.Pod)
+		if !ok {
+			return nil, "", fmt.Errorf("unexpected object type for kind pods")
+		}
+		return pod, pod.Spec.NodeName, nil
+	case "bindings":
+		binding, ok := a.GetObject().(*api.Binding)
+		if !ok {
+			return nil, "", fmt.Errorf("unexpected object type for kind bindings")
+		}
+		pod, err := n.client.Pods(a.GetNamespace()).Get(binding.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		return pod, binding.Target.Name, nil
+	}
+	return nil, "", nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+)
+
+// ObserverNodeFuncBindings aggregates, per node, the CPU and Memory requested by every pod
+// currently bound to it, so a ResourceController's ResourceControllerGroupByNode group can compare
+// what's scheduled against what the node can allocate.
+func (o *observer) ObserverNodeFuncBindings() []resourcecontroller.ObserverNodeFuncBinding {
+	return []resourcecontroller.ObserverNodeFuncBinding{
+		{
+			RuleType:     api.ResourceControllerRuleTypeRequest,
+			ResourceName: "CPU",
+			Func: func(store cache.Store, nodeName string) (*resource.Quantity, error) {
+				val := int64(0)
+				for _, pod := range o.factory.PodsForNode(nodeName) {
+					for _, container := range pod.Spec.Containers {
+						val = val + container.CPU.MilliValue()
+					}
+				}
+				return resource.NewMilliQuantity(val, resource.DecimalSI), nil
+			},
+		},
+		{
+			RuleType:     api.ResourceControllerRuleTypeRequest,
+			ResourceName: "Memory",
+			Func: func(store cache.Store, nodeName string) (*resource.Quantity, error) {
+				val := int64(0)
+				for _, pod := range o.factory.PodsForNode(nodeName) {
+					for _, container := range pod.Spec.Containers {
+						val = val + container.Memory.Value()
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+	}
+}
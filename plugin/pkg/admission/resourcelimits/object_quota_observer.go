@@ -0,0 +1,269 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+)
+
+func (o *observer) daemonSets(store cache.Store, controller api.ResourceController) (*api.DaemonSetList, error) {
+	all := o.factory.DaemonSets(controller.Namespace)
+	items := make([]api.DaemonSet, 0, len(all))
+	for _, daemonSet := range all {
+		if resourcecontroller.LabelsMatchSelector(daemonSet.Labels, controller.Spec.Selector) {
+			items = append(items, daemonSet)
+		}
+	}
+	return &api.DaemonSetList{Items: items}, nil
+}
+
+func (o *observer) persistentVolumeClaims(store cache.Store, controller api.ResourceController) (*api.PersistentVolumeClaimList, error) {
+	all := o.factory.PersistentVolumeClaims(controller.Namespace)
+	items := make([]api.PersistentVolumeClaim, 0, len(all))
+	for _, claim := range all {
+		if resourcecontroller.LabelsMatchSelector(claim.Labels, controller.Spec.Selector) {
+			items = append(items, claim)
+		}
+	}
+	return &api.PersistentVolumeClaimList{Items: items}, nil
+}
+
+func (o *observer) secrets(store cache.Store, controller api.ResourceController) (*api.SecretList, error) {
+	all := o.factory.Secrets(controller.Namespace)
+	items := make([]api.Secret, 0, len(all))
+	for _, secret := range all {
+		if resourcecontroller.LabelsMatchSelector(secret.Labels, controller.Spec.Selector) {
+			items = append(items, secret)
+		}
+	}
+	return &api.SecretList{Items: items}, nil
+}
+
+func (o *observer) configMaps(store cache.Store, controller api.ResourceController) (*api.ConfigMapList, error) {
+	all := o.factory.ConfigMaps(controller.Namespace)
+	items := make([]api.ConfigMap, 0, len(all))
+	for _, configMap := range all {
+		if resourcecontroller.LabelsMatchSelector(configMap.Labels, controller.Spec.Selector) {
+			items = append(items, configMap)
+		}
+	}
+	return &api.ConfigMapList{Items: items}, nil
+}
+
+// daemonSetObserverFuncBindings caps the number of DaemonSets a namespace may create. A DaemonSet
+// implies one pod per eligible node, so this is the closest this observer can come to bounding the
+// pod count a DaemonSet will fan out to without duplicating the scheduler's node-selection logic.
+func (o *observer) daemonSetObserverFuncBindings() []resourcecontroller.ObserverFuncBinding {
+	groupBy := api.ResourceControllerGroupByDaemonSet
+
+	return []resourcecontroller.ObserverFuncBinding{
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "DaemonSets",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.daemonSets(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewQuantity(int64(len(items.Items)), resource.DecimalSI), nil
+			},
+		},
+	}
+}
+
+// serviceGroupObserverFuncBindings caps Services more granularly than the blunt per-namespace
+// Services count already tracked under ResourceControllerGroupByNamespace: separately limiting how
+// many are LoadBalancer-typed and how many node ports they expose in total (a service with two
+// node ports counts twice), since both are cluster-scarce resources.
+func (o *observer) serviceGroupObserverFuncBindings() []resourcecontroller.ObserverFuncBinding {
+	groupBy := api.ResourceControllerGroupByService
+
+	return []resourcecontroller.ObserverFuncBinding{
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "Services",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.services(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewQuantity(int64(len(items.Items)), resource.DecimalSI), nil
+			},
+		},
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "LoadBalancers",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.services(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				val := int64(0)
+				for _, item := range items.Items {
+					if item.Spec.Type == api.ServiceTypeLoadBalancer {
+						val++
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "NodePorts",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.services(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				val := int64(0)
+				for _, item := range items.Items {
+					for _, port := range item.Spec.Ports {
+						if port.NodePort != 0 {
+							val++
+						}
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+	}
+}
+
+// persistentVolumeClaimObserverFuncBindings caps the count of claims and their aggregate requested
+// storage. Storage is summed across every StorageClass rather than broken out per-class: per-class
+// accounting would need a ResourceName per observed class name, which the fixed GroupBy/RuleType/
+// ResourceName triple ObserverFuncBinding models don't support without further plumbing.
+func (o *observer) persistentVolumeClaimObserverFuncBindings() []resourcecontroller.ObserverFuncBinding {
+	groupBy := api.ResourceControllerGroupByPersistentVolumeClaim
+
+	return []resourcecontroller.ObserverFuncBinding{
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "PersistentVolumeClaims",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.persistentVolumeClaims(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewQuantity(int64(len(items.Items)), resource.DecimalSI), nil
+			},
+		},
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "Storage",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.persistentVolumeClaims(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				val := int64(0)
+				for _, item := range items.Items {
+					if request, ok := item.Spec.Resources.Requests["storage"]; ok {
+						val += request.Value()
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+	}
+}
+
+// secretObserverFuncBindings caps the count of Secrets and their aggregate encoded size in bytes.
+func (o *observer) secretObserverFuncBindings() []resourcecontroller.ObserverFuncBinding {
+	groupBy := api.ResourceControllerGroupBySecret
+
+	return []resourcecontroller.ObserverFuncBinding{
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "Secrets",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.secrets(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewQuantity(int64(len(items.Items)), resource.DecimalSI), nil
+			},
+		},
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "SecretBytes",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.secrets(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				val := int64(0)
+				for _, item := range items.Items {
+					for _, data := range item.Data {
+						val += int64(len(data))
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+	}
+}
+
+// configMapObserverFuncBindings caps the count of ConfigMaps and their aggregate encoded size in
+// bytes, mirroring secretObserverFuncBindings.
+func (o *observer) configMapObserverFuncBindings() []resourcecontroller.ObserverFuncBinding {
+	groupBy := api.ResourceControllerGroupByConfigMap
+
+	return []resourcecontroller.ObserverFuncBinding{
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "ConfigMaps",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.configMaps(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewQuantity(int64(len(items.Items)), resource.DecimalSI), nil
+			},
+		},
+		{
+			GroupBy:      groupBy,
+			RuleType:     api.ResourceControllerRuleTypeMax,
+			ResourceName: "ConfigMapBytes",
+			Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+				items, err := o.configMaps(store, controller)
+				if err != nil {
+					return nil, err
+				}
+				val := int64(0)
+				for _, item := range items.Items {
+					for _, data := range item.Data {
+						val += int64(len(data))
+					}
+				}
+				return resource.NewQuantity(val, resource.DecimalSI), nil
+			},
+		},
+	}
+}
@@ -21,51 +21,78 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
 )
 
+// observerStopCh is closed by Stop to shut down the shared informer factory's watch goroutine
+// started in init. It's kept on a package-level var, rather than discarded, because
+// RegisterObserver's factory func returns no other handle a caller could use to stop it later.
+var observerStopCh = make(chan struct{})
+
 func init() {
 	resourcecontroller.RegisterObserver("ResourceLimits", func(client client.Interface) (resourcecontroller.Observer, error) {
-		return &observer{client: client}, nil
+		factory := newSharedInformerFactory(client, defaultResyncPeriod)
+		factory.Run(observerStopCh)
+		return &observer{factory: factory}, nil
 	})
 }
 
+// Stop shuts down the shared informer factory's watch goroutine started in init. A process that
+// loads this admission plugin should call it on shutdown so the goroutine doesn't outlive the
+// plugin's usefulness.
+func Stop() {
+	close(observerStopCh)
+}
+
+// observer makes ResourceLimits observations against a sharedInformerFactory's cache rather than
+// issuing a fresh List to the apiserver for every ResourceController reconciled in a namespace. The
+// store parameter each ObserverFunc still receives is unused now that the factory itself is the
+// cache, but is kept so the resourcecontroller.ObserverFunc signature is unchanged.
 type observer struct {
-	client client.Interface
+	factory *sharedInformerFactory
 }
 
-func (o *observer) pods(store cache.Store, namespace string) (*api.PodList, error) {
-	obj, exists := store.Get("pods")
-	if exists {
-		items := obj.(*api.PodList)
-		return items, nil
+// defaultPodFilter is applied by every pod-based binding below. Its zero value already excludes
+// PodSucceeded/PodFailed pods, so a crash-looping Job no longer permanently consumes namespace quota.
+var defaultPodFilter = resourcecontroller.PodFilter{}
+
+// pods lists the pods controller's quota applies to: cached pods in its namespace that match both
+// filter (a static, phase-based restriction shared by every binding) and controller's own
+// Spec.Scopes/Spec.Selector (which narrow quota to a subset of the namespace's pods).
+func (o *observer) pods(store cache.Store, controller api.ResourceController, filter resourcecontroller.PodFilter) (*api.PodList, error) {
+	all := o.factory.Pods(controller.Namespace)
+	items := make([]api.Pod, 0, len(all))
+	for _, pod := range all {
+		if filter.Matches(pod) && resourcecontroller.PodMatchesController(controller, pod) {
+			items = append(items, pod)
+		}
 	}
-	items, err := o.client.Pods(namespace).List(labels.Everything())
-	store.Add("pods", items)
-	return items, err
+	return &api.PodList{Items: items}, nil
 }
 
-func (o *observer) services(store cache.Store, namespace string) (*api.ServiceList, error) {
-	obj, exists := store.Get("services")
-	if exists {
-		items := obj.(*api.ServiceList)
-		return items, nil
+// services lists the cached services in controller's namespace that match its Spec.Selector.
+func (o *observer) services(store cache.Store, controller api.ResourceController) (*api.ServiceList, error) {
+	all := o.factory.Services(controller.Namespace)
+	items := make([]api.Service, 0, len(all))
+	for _, service := range all {
+		if resourcecontroller.LabelsMatchSelector(service.Labels, controller.Spec.Selector) {
+			items = append(items, service)
+		}
 	}
-	items, err := o.client.Services(namespace).List(labels.Everything())
-	store.Add("services", items)
-	return items, err
+	return &api.ServiceList{Items: items}, nil
 }
 
-func (o *observer) replicationControllers(store cache.Store, namespace string) (*api.ReplicationControllerList, error) {
-	obj, exists := store.Get("replicationControllers")
-	if exists {
-		items := obj.(*api.ReplicationControllerList)
-		return items, nil
+// replicationControllers lists the cached replication controllers in controller's namespace that
+// match its Spec.Selector.
+func (o *observer) replicationControllers(store cache.Store, controller api.ResourceController) (*api.ReplicationControllerList, error) {
+	all := o.factory.ReplicationControllers(controller.Namespace)
+	items := make([]api.ReplicationController, 0, len(all))
+	for _, rc := range all {
+		if resourcecontroller.LabelsMatchSelector(rc.Labels, controller.Spec.Selector) {
+			items = append(items, rc)
+		}
 	}
-	items, err := o.client.ReplicationControllers(namespace).List(labels.Everything())
-	store.Add("replicationControllers", items)
-	return items, err
+	return &api.ReplicationControllerList{Items: items}, nil
 }
 
 // return observer func bindings for namespace scope
@@ -78,8 +105,9 @@ func (o *observer) namespaceObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "CPU",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -96,8 +124,9 @@ func (o *observer) namespaceObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Memory",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -114,8 +143,9 @@ func (o *observer) namespaceObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Pods",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -126,8 +156,8 @@ func (o *observer) namespaceObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Services",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.services(store, namespace)
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.services(store, controller)
 			if err != nil {
 				return nil, err
 			}
@@ -138,8 +168,8 @@ func (o *observer) namespaceObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "ReplicationControllers",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.replicationControllers(store, namespace)
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.replicationControllers(store, controller)
 			if err != nil {
 				return nil, err
 			}
@@ -158,8 +188,9 @@ func (o *observer) containerObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Memory",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -178,8 +209,9 @@ func (o *observer) containerObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMin,
 		ResourceName: "Memory",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -197,8 +229,9 @@ func (o *observer) containerObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "CPU",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -217,8 +250,9 @@ func (o *observer) containerObserverFuncBindings() []resourcecontroller.Observer
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMin,
 		ResourceName: "CPU",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -244,8 +278,9 @@ func (o *observer) podObserverFuncBindings() []resourcecontroller.ObserverFuncBi
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "CPU",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -265,8 +300,9 @@ func (o *observer) podObserverFuncBindings() []resourcecontroller.ObserverFuncBi
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMin,
 		ResourceName: "CPU",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -286,8 +322,9 @@ func (o *observer) podObserverFuncBindings() []resourcecontroller.ObserverFuncBi
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Memory",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -307,8 +344,9 @@ func (o *observer) podObserverFuncBindings() []resourcecontroller.ObserverFuncBi
 		GroupBy:      groupBy,
 		RuleType:     api.ResourceControllerRuleTypeMin,
 		ResourceName: "Memory",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.pods(store, namespace)
+		PodFilter:    defaultPodFilter,
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.pods(store, controller, defaultPodFilter)
 			if err != nil {
 				return nil, err
 			}
@@ -335,8 +373,8 @@ func (o *observer) replicationControllerObserverFuncBindings() []resourcecontrol
 		GroupBy:      api.ResourceControllerGroupByReplicationController,
 		RuleType:     api.ResourceControllerRuleTypeMax,
 		ResourceName: "Replicas",
-		Func: func(store cache.Store, namespace string) (*resource.Quantity, error) {
-			items, err := o.replicationControllers(store, namespace)
+		Func: func(store cache.Store, controller api.ResourceController) (*resource.Quantity, error) {
+			items, err := o.replicationControllers(store, controller)
 			if err != nil {
 				return nil, err
 			}
@@ -360,6 +398,11 @@ func (o *observer) ObserverFuncBindings() []resourcecontroller.ObserverFuncBindi
 	observerFuncBindings = append(observerFuncBindings, o.containerObserverFuncBindings()...)
 	observerFuncBindings = append(observerFuncBindings, o.podObserverFuncBindings()...)
 	observerFuncBindings = append(observerFuncBindings, o.replicationControllerObserverFuncBindings()...)
+	observerFuncBindings = append(observerFuncBindings, o.daemonSetObserverFuncBindings()...)
+	observerFuncBindings = append(observerFuncBindings, o.serviceGroupObserverFuncBindings()...)
+	observerFuncBindings = append(observerFuncBindings, o.persistentVolumeClaimObserverFuncBindings()...)
+	observerFuncBindings = append(observerFuncBindings, o.secretObserverFuncBindings()...)
+	observerFuncBindings = append(observerFuncBindings, o.configMapObserverFuncBindings()...)
 
 	return observerFuncBindings
 }
@@ -0,0 +1,100 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcelimits
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+)
+
+// namespaceFuncByName returns the ObserverFunc registered under resourceName in the namespace group,
+// failing the test immediately if it isn't found.
+func namespaceFuncByName(t *testing.T, o *observer, resourceName api.ResourceName) resourcecontroller.ObserverFunc {
+	for _, binding := range o.namespaceObserverFuncBindings() {
+		if binding.ResourceName == resourceName {
+			return binding.Func
+		}
+	}
+	t.Fatalf("no namespace binding registered for resource %v", resourceName)
+	return nil
+}
+
+// TestNamespaceObserverPodLifecycle verifies that a pod transitioning Pending -> Running -> Succeeded
+// is counted toward CPU/Memory/Pods usage while Pending and Running, and is excluded once Succeeded,
+// matching defaultPodFilter's default exclusion of terminal pods.
+func TestNamespaceObserverPodLifecycle(t *testing.T) {
+	const namespace = "default"
+
+	pod := api.Pod{
+		ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: "test-pod"},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					CPU:    *resource.NewMilliQuantity(500, resource.DecimalSI),
+					Memory: *resource.NewQuantity(1024, resource.DecimalSI),
+				},
+			},
+		},
+	}
+
+	factory := newSharedInformerFactory(nil, defaultResyncPeriod)
+	o := &observer{factory: factory}
+
+	cpuFunc := namespaceFuncByName(t, o, "CPU")
+	memoryFunc := namespaceFuncByName(t, o, "Memory")
+	podsFunc := namespaceFuncByName(t, o, "Pods")
+
+	controller := api.ResourceController{ObjectMeta: api.ObjectMeta{Namespace: namespace}}
+
+	assertObservation := func(phase api.PodPhase, wantCPU, wantMemory, wantPods int64) {
+		pod.Status.Phase = phase
+		factory.lock.Lock()
+		factory.podsByNamespace = map[string][]api.Pod{namespace: {pod}}
+		factory.lock.Unlock()
+
+		cpu, err := cpuFunc(nil, controller)
+		if err != nil {
+			t.Fatalf("phase %v: unexpected error observing CPU: %v", phase, err)
+		}
+		if cpu.MilliValue() != wantCPU {
+			t.Errorf("phase %v: CPU = %v, want %vm", phase, cpu.MilliValue(), wantCPU)
+		}
+
+		memory, err := memoryFunc(nil, controller)
+		if err != nil {
+			t.Fatalf("phase %v: unexpected error observing Memory: %v", phase, err)
+		}
+		if memory.Value() != wantMemory {
+			t.Errorf("phase %v: Memory = %v, want %v", phase, memory.Value(), wantMemory)
+		}
+
+		pods, err := podsFunc(nil, controller)
+		if err != nil {
+			t.Fatalf("phase %v: unexpected error observing Pods: %v", phase, err)
+		}
+		if pods.Value() != wantPods {
+			t.Errorf("phase %v: Pods = %v, want %v", phase, pods.Value(), wantPods)
+		}
+	}
+
+	assertObservation(api.PodPending, 500, 1024, 1)
+	assertObservation(api.PodRunning, 500, 1024, 1)
+	assertObservation(api.PodSucceeded, 0, 0, 0)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolumeclaim
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/admission/resourcelimits"
+)
+
+func init() {
+	resourcelimits.RegisterAdmissionFunc("ResourceLimitsPersistentVolumeClaim", admissionFunc)
+}
+
+var resourceNameToMessage = map[api.ResourceName]string{
+	"PersistentVolumeClaims": "Limited to %v persistent volume claims in namespace %v",
+	"Storage":                "Limited to %v storage requested in namespace %v",
+}
+
+func makeObservation(status *api.ResourceControllerStatus, resourceName api.ResourceName, newQuantity *resource.Quantity) {
+	_, observedAllocatedByGroup := resourcecontroller.AllowedAndAllocated(status)
+	observedAllocatedGroupRules := observedAllocatedByGroup[api.ResourceControllerGroupByPersistentVolumeClaim]
+	observedAllocatedGroupRulesMax := observedAllocatedGroupRules[api.ResourceControllerRuleTypeMax]
+	observedAllocatedGroupRulesMax[resourceName] = *newQuantity
+}
+
+func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	dirty := false
+
+	if a.GetOperation() != "CREATE" {
+		return dirty, nil
+	}
+
+	if a.GetKind() != "persistentVolumeClaims" {
+		return dirty, nil
+	}
+
+	allowedByGroup, allocatedByGroup := resourcecontroller.AllowedAndAllocated(&input.Status)
+	allowedGroupRulesMax := allowedByGroup[api.ResourceControllerGroupByPersistentVolumeClaim][api.ResourceControllerRuleTypeMax]
+	if allowedGroupRulesMax == nil {
+		return dirty, nil
+	}
+	allocatedGroupRulesMax := allocatedByGroup[api.ResourceControllerGroupByPersistentVolumeClaim][api.ResourceControllerRuleTypeMax]
+
+	claim := a.GetObject().(*api.PersistentVolumeClaim)
+
+	if !resourcecontroller.LabelsMatchSelector(claim.Labels, input.Spec.Selector) {
+		return dirty, nil
+	}
+
+	requestedStorage := int64(0)
+	if request, ok := claim.Spec.Resources.Requests["storage"]; ok {
+		requestedStorage = request.Value()
+	}
+	deltaByResourceName := map[api.ResourceName]int64{
+		"PersistentVolumeClaims": 1,
+		"Storage":                requestedStorage,
+	}
+
+	for resourceName, limit := range allowedGroupRulesMax {
+		delta, tracked := deltaByResourceName[resourceName]
+		if !tracked {
+			continue
+		}
+
+		observed, observationExists := allocatedGroupRulesMax[resourceName]
+		if !observationExists {
+			return dirty, apierrors.NewForbidden(a.GetKind(), claim.Name, fmt.Errorf("Unable to admit resource, waiting for resource observation to complete."))
+		}
+
+		if observed.Value()+delta > limit.Value() {
+			return dirty, apierrors.NewForbidden(a.GetKind(), claim.Name,
+				fmt.Errorf(resourceNameToMessage[resourceName], limit.String(), input.Namespace))
+		}
+
+		makeObservation(&observation.Status, resourceName, resource.NewQuantity(observed.Value()+delta, resource.DecimalSI))
+		dirty = true
+	}
+
+	return dirty, nil
+}
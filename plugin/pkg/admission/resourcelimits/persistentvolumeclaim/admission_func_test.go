@@ -0,0 +1,132 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolumeclaim
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving admissionFunc in tests.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string      { return f.namespace }
+func (f fakeAttributes) GetKind() string           { return f.kind }
+func (f fakeAttributes) GetOperation() string      { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+func newClaimAttributes(claim *api.PersistentVolumeClaim) admission.Attributes {
+	return fakeAttributes{
+		namespace: claim.Namespace,
+		kind:      "persistentVolumeClaims",
+		operation: "CREATE",
+		object:    claim,
+	}
+}
+
+func claimWithStorageRequest(namespace, name string, storage int64, selector map[string]string) *api.PersistentVolumeClaim {
+	claim := &api.PersistentVolumeClaim{ObjectMeta: api.ObjectMeta{Namespace: namespace, Name: name, Labels: selector}}
+	claim.Spec.Resources.Requests = api.ResourceList{
+		"storage": *resource.NewQuantity(storage, resource.DecimalSI),
+	}
+	return claim
+}
+
+func controllerWithStorageMax(maxClaims, allocatedClaims, maxStorage, allocatedStorage int64, selector map[string]string) *api.ResourceController {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByPersistentVolumeClaim,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"PersistentVolumeClaims": *resource.NewQuantity(maxClaims, resource.DecimalSI),
+					"Storage":                *resource.NewQuantity(maxStorage, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByPersistentVolumeClaim,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"PersistentVolumeClaims": *resource.NewQuantity(allocatedClaims, resource.DecimalSI),
+					"Storage":                *resource.NewQuantity(allocatedStorage, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+	ctrl.Spec.Selector = selector
+	return ctrl
+}
+
+// TestAdmissionFunc verifies admissionFunc only charges a PersistentVolumeClaim against the quota
+// when it's selected by the controller's spec.Selector, and is a no-op for one that isn't.
+func TestAdmissionFunc(t *testing.T) {
+	table := []struct {
+		name      string
+		selector  map[string]string
+		labels    map[string]string
+		wantErr   bool
+		wantDirty bool
+	}{
+		{"no selector matches everything, room left", nil, nil, false, true},
+		{"matching selector, room left", map[string]string{"app": "db"}, map[string]string{"app": "db"}, false, true},
+		{"non-matching selector is skipped entirely", map[string]string{"app": "db"}, map[string]string{"app": "web"}, false, false},
+	}
+
+	for _, item := range table {
+		input := controllerWithStorageMax(5, 4, 100, 10, item.selector)
+		claim := claimWithStorageRequest("default", "claim", 5, item.labels)
+
+		dirty, err := admissionFunc(newClaimAttributes(claim), input, &api.ResourceObservation{Status: input.Status}, nil)
+		if (err != nil) != item.wantErr {
+			t.Errorf("%s: admissionFunc() error = %v, wantErr %v", item.name, err, item.wantErr)
+		}
+		if dirty != item.wantDirty {
+			t.Errorf("%s: admissionFunc() dirty = %v, want %v", item.name, dirty, item.wantDirty)
+		}
+	}
+}
+
+// TestAdmissionFuncDeniesAtMaxClaims verifies admissionFunc rejects a claim once the namespace is
+// already at its allowed max number of claims.
+func TestAdmissionFuncDeniesAtMaxClaims(t *testing.T) {
+	input := controllerWithStorageMax(1, 1, 100, 0, nil)
+	claim := claimWithStorageRequest("default", "claim", 1, nil)
+
+	if _, err := admissionFunc(newClaimAttributes(claim), input, &api.ResourceObservation{Status: input.Status}, nil); err == nil {
+		t.Errorf("expected an error admitting a claim once the namespace is already at its max claim count")
+	}
+}
+
+// TestAdmissionFuncDeniesOverStorage verifies admissionFunc rejects a claim whose requested storage
+// would push the namespace's allocated storage over its allowed max.
+func TestAdmissionFuncDeniesOverStorage(t *testing.T) {
+	input := controllerWithStorageMax(5, 0, 10, 5, nil)
+	claim := claimWithStorageRequest("default", "claim", 10, nil)
+
+	if _, err := admissionFunc(newClaimAttributes(claim), input, &api.ResourceObservation{Status: input.Status}, nil); err == nil {
+		t.Errorf("expected an error admitting a claim whose requested storage exceeds the remaining quota")
+	}
+}
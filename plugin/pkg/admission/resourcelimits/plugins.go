@@ -17,17 +17,37 @@ limitations under the License.
 package resourcelimits
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/golang/glog"
 )
 
+// DefaultPriority is the priority assigned to a plug-in registered with RegisterAdmissionFunc. Lower
+// priority plug-ins run first; plug-ins that must observe the effects of others should register with
+// a higher priority via RegisterAdmissionFuncWithPriority.
+const DefaultPriority = 0
+
+// registeredAdmissionFunc is a plug-in together with the name and priority it was registered under
+type registeredAdmissionFunc struct {
+	name     string
+	priority int
+	fn       AdmissionFunc
+}
+
 // All registered resource observer options.
 var pluginsMutex sync.Mutex
-var plugins = make(map[string]AdmissionFunc)
+var plugins = make(map[string]registeredAdmissionFunc)
 
-// RegisterAdmissionFunc registers an AdmissionFunc plug-in
+// RegisterAdmissionFunc registers an AdmissionFunc plug-in at DefaultPriority
 func RegisterAdmissionFunc(name string, admissionFunc AdmissionFunc) {
+	RegisterAdmissionFuncWithPriority(name, DefaultPriority, admissionFunc)
+}
+
+// RegisterAdmissionFuncWithPriority registers an AdmissionFunc plug-in to run at the given priority.
+// GetAdmissionFuncs always returns plug-ins ordered by ascending priority, breaking ties by name, so
+// that a given set of registered plug-ins is applied in the same order on every admission check.
+func RegisterAdmissionFuncWithPriority(name string, priority int, admissionFunc AdmissionFunc) {
 	pluginsMutex.Lock()
 	defer pluginsMutex.Unlock()
 
@@ -36,18 +56,37 @@ func RegisterAdmissionFunc(name string, admissionFunc AdmissionFunc) {
 		glog.Fatalf("AdmissionFunc plugin with name: %q was registered twice", name)
 	}
 
-	glog.V(1).Infof("Registered AdmissionFunc plugin with name: %q", name)
-	plugins[name] = admissionFunc
+	glog.V(1).Infof("Registered AdmissionFunc plugin with name: %q at priority %d", name, priority)
+	plugins[name] = registeredAdmissionFunc{name: name, priority: priority, fn: admissionFunc}
 }
 
-// GetAdmissionFuncs returns each registered plug-in
+// GetAdmissionFuncs returns each registered plug-in ordered by ascending priority, breaking ties by
+// the name the plug-in was registered under, so that ordering is deterministic across calls.
 func GetAdmissionFuncs() []AdmissionFunc {
 	pluginsMutex.Lock()
 	defer pluginsMutex.Unlock()
 
-	admissionFuncs := []AdmissionFunc{}
-	for _, admissionFunc := range plugins {
-		admissionFuncs = append(admissionFuncs, admissionFunc)
+	ordered := make([]registeredAdmissionFunc, 0, len(plugins))
+	for _, plugin := range plugins {
+		ordered = append(ordered, plugin)
+	}
+	sort.Sort(byPriorityThenName(ordered))
+
+	admissionFuncs := make([]AdmissionFunc, 0, len(ordered))
+	for _, plugin := range ordered {
+		admissionFuncs = append(admissionFuncs, plugin.fn)
 	}
 	return admissionFuncs
 }
+
+// byPriorityThenName sorts registeredAdmissionFunc by ascending priority, then by name
+type byPriorityThenName []registeredAdmissionFunc
+
+func (b byPriorityThenName) Len() int      { return len(b) }
+func (b byPriorityThenName) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byPriorityThenName) Less(i, j int) bool {
+	if b[i].priority != b[j].priority {
+		return b[i].priority < b[j].priority
+	}
+	return b[i].name < b[j].name
+}
@@ -52,6 +52,10 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 	obj := a.GetObject()
 	pod := obj.(*api.Pod)
 
+	if !resourcecontroller.PodMatchesController(*input, *pod) {
+		return dirty, nil
+	}
+
 	memoryUsage := int64(0)
 	cpuUsage := int64(0)
 	for _, container := range pod.Spec.Containers {
@@ -0,0 +1,113 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podsecurity
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/admission/resourcelimits"
+)
+
+func init() {
+	resourcelimits.RegisterAdmissionFunc("PodSecurityNamespace", admissionFunc)
+}
+
+// admissionFunc enforces a namespace's configured pod-security profile against incoming pods. The
+// profile itself is not a resource quantity, so it rides on a ResourceController rule the same way
+// every other resourcelimits plug-in does: as a namespace-scoped group with rule type
+// api.ResourceControllerRuleTypePodSecurityProfile, naming the active profile by the sole
+// ResourceName key present in its Resources.
+func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	dirty := false
+
+	if a.GetOperation() != "CREATE" {
+		return dirty, nil
+	}
+
+	if a.GetKind() != "pods" {
+		return dirty, nil
+	}
+
+	allowedByGroup, _ := resourcecontroller.AllowedAndAllocated(&input.Status)
+	groupRules := allowedByGroup[api.ResourceControllerGroupByNamespace]
+	if groupRules == nil {
+		return dirty, nil
+	}
+	profile := groupRules[api.ResourceControllerRuleTypePodSecurityProfile]
+	if profile == nil {
+		return dirty, nil
+	}
+
+	obj := a.GetObject()
+	pod := obj.(*api.Pod)
+
+	if _, restricted := profile["Restricted"]; restricted {
+		if err := checkRestricted(a, pod); err != nil {
+			return dirty, err
+		}
+		return dirty, nil
+	}
+	if _, baseline := profile["Baseline"]; baseline {
+		if err := checkBaseline(a, pod); err != nil {
+			return dirty, err
+		}
+	}
+	return dirty, nil
+}
+
+// checkBaseline rejects privileged containers and pods that share the host's network, PID, or IPC namespaces
+func checkBaseline(a admission.Attributes, pod *api.Pod) error {
+	if pod.Spec.HostNetwork {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("baseline pod security profile disallows hostNetwork"))
+	}
+	if pod.Spec.HostPID {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("baseline pod security profile disallows hostPID"))
+	}
+	if pod.Spec.HostIPC {
+		return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("baseline pod security profile disallows hostIPC"))
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Privileged {
+			return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("baseline pod security profile disallows privileged container %q", container.Name))
+		}
+	}
+	return nil
+}
+
+// checkRestricted applies everything checkBaseline does, plus disallows hostPath volumes and
+// containers that run as UID 0
+func checkRestricted(a admission.Attributes, pod *api.Pod) error {
+	if err := checkBaseline(a, pod); err != nil {
+		return err
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath != nil {
+			return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("restricted pod security profile disallows hostPath volume %q", volume.Name))
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.RunAsUser != nil && *container.RunAsUser == 0 {
+			return apierrors.NewForbidden(a.GetKind(), pod.Name, fmt.Errorf("restricted pod security profile disallows container %q running as uid 0", container.Name))
+		}
+	}
+	return nil
+}
@@ -48,6 +48,11 @@ func admissionFunc(a admission.Attributes, input *api.ResourceController, observ
 
 	obj := a.GetObject()
 	replicationController := obj.(*api.ReplicationController)
+
+	if !resourcecontroller.LabelsMatchSelector(replicationController.Labels, input.Spec.Selector) {
+		return false, nil
+	}
+
 	replicas := int64(replicationController.Spec.Replicas)
 
 	for ruleType, resources := range groupRules {
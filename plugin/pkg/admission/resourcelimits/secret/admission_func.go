@@ -0,0 +1,127 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secret admits Secrets and ConfigMaps, enforcing a namespace's count and aggregate
+// encoded-size quota for each kind independently (ResourceControllerGroupBySecret and
+// ResourceControllerGroupByConfigMap, respectively).
+package secret
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/admission/resourcelimits"
+)
+
+func init() {
+	resourcelimits.RegisterAdmissionFunc("ResourceLimitsSecret", admissionFunc)
+}
+
+var resourceNameToMessage = map[api.ResourceName]string{
+	"Secrets":        "Limited to %v secrets in namespace %v",
+	"SecretBytes":    "Limited to %v bytes of secret data in namespace %v",
+	"ConfigMaps":     "Limited to %v config maps in namespace %v",
+	"ConfigMapBytes": "Limited to %v bytes of config map data in namespace %v",
+}
+
+func makeObservation(status *api.ResourceControllerStatus, groupBy api.ResourceControllerGroupBy, resourceName api.ResourceName, newQuantity *resource.Quantity) {
+	_, observedAllocatedByGroup := resourcecontroller.AllowedAndAllocated(status)
+	observedAllocatedGroupRules := observedAllocatedByGroup[groupBy]
+	observedAllocatedGroupRulesMax := observedAllocatedGroupRules[api.ResourceControllerRuleTypeMax]
+	observedAllocatedGroupRulesMax[resourceName] = *newQuantity
+}
+
+func enforce(input *api.ResourceController, observation *api.ResourceObservation, groupBy api.ResourceControllerGroupBy, kind, name string, deltaByResourceName map[api.ResourceName]int64) (bool, error) {
+	dirty := false
+
+	allowedByGroup, allocatedByGroup := resourcecontroller.AllowedAndAllocated(&input.Status)
+	allowedGroupRulesMax := allowedByGroup[groupBy][api.ResourceControllerRuleTypeMax]
+	if allowedGroupRulesMax == nil {
+		return dirty, nil
+	}
+	allocatedGroupRulesMax := allocatedByGroup[groupBy][api.ResourceControllerRuleTypeMax]
+
+	for resourceName, limit := range allowedGroupRulesMax {
+		delta, tracked := deltaByResourceName[resourceName]
+		if !tracked {
+			continue
+		}
+
+		observed, observationExists := allocatedGroupRulesMax[resourceName]
+		if !observationExists {
+			return dirty, apierrors.NewForbidden(kind, name, fmt.Errorf("Unable to admit resource, waiting for resource observation to complete."))
+		}
+
+		if observed.Value()+delta > limit.Value() {
+			return dirty, apierrors.NewForbidden(kind, name,
+				fmt.Errorf(resourceNameToMessage[resourceName], limit.String(), input.Namespace))
+		}
+
+		makeObservation(&observation.Status, groupBy, resourceName, resource.NewQuantity(observed.Value()+delta, resource.DecimalSI))
+		dirty = true
+	}
+
+	return dirty, nil
+}
+
+func secretBytes(secret *api.Secret) int64 {
+	val := int64(0)
+	for _, data := range secret.Data {
+		val += int64(len(data))
+	}
+	return val
+}
+
+func configMapBytes(configMap *api.ConfigMap) int64 {
+	val := int64(0)
+	for _, data := range configMap.Data {
+		val += int64(len(data))
+	}
+	return val
+}
+
+func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	if a.GetOperation() != "CREATE" {
+		return false, nil
+	}
+
+	switch a.GetKind() {
+	case "secrets":
+		secret := a.GetObject().(*api.Secret)
+		if !resourcecontroller.LabelsMatchSelector(secret.Labels, input.Spec.Selector) {
+			return false, nil
+		}
+		return enforce(input, observation, api.ResourceControllerGroupBySecret, a.GetKind(), secret.Name, map[api.ResourceName]int64{
+			"Secrets":     1,
+			"SecretBytes": secretBytes(secret),
+		})
+	case "configMaps":
+		configMap := a.GetObject().(*api.ConfigMap)
+		if !resourcecontroller.LabelsMatchSelector(configMap.Labels, input.Spec.Selector) {
+			return false, nil
+		}
+		return enforce(input, observation, api.ResourceControllerGroupByConfigMap, a.GetKind(), configMap.Name, map[api.ResourceName]int64{
+			"ConfigMaps":     1,
+			"ConfigMapBytes": configMapBytes(configMap),
+		})
+	}
+	return false, nil
+}
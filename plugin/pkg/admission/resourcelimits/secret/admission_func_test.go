@@ -0,0 +1,183 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving admissionFunc in tests.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string      { return f.namespace }
+func (f fakeAttributes) GetKind() string           { return f.kind }
+func (f fakeAttributes) GetOperation() string      { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+func newSecretAttributes(secret *api.Secret) admission.Attributes {
+	return fakeAttributes{
+		namespace: secret.Namespace,
+		kind:      "secrets",
+		operation: "CREATE",
+		object:    secret,
+	}
+}
+
+func newConfigMapAttributes(configMap *api.ConfigMap) admission.Attributes {
+	return fakeAttributes{
+		namespace: configMap.Namespace,
+		kind:      "configMaps",
+		operation: "CREATE",
+		object:    configMap,
+	}
+}
+
+func controllerWithSecretsMax(max, allocated int64, selector map[string]string) *api.ResourceController {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupBySecret,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"Secrets": *resource.NewQuantity(max, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupBySecret,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"Secrets": *resource.NewQuantity(allocated, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+	ctrl.Spec.Selector = selector
+	return ctrl
+}
+
+func controllerWithConfigMapsMax(max, allocated int64, selector map[string]string) *api.ResourceController {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByConfigMap,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"ConfigMaps": *resource.NewQuantity(max, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByConfigMap,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"ConfigMaps": *resource.NewQuantity(allocated, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+	ctrl.Spec.Selector = selector
+	return ctrl
+}
+
+// TestAdmissionFuncSecrets verifies admissionFunc only charges a Secret against the quota when
+// it's selected by the controller's spec.Selector, and is a no-op for one that isn't.
+func TestAdmissionFuncSecrets(t *testing.T) {
+	table := []struct {
+		name      string
+		selector  map[string]string
+		labels    map[string]string
+		wantErr   bool
+		wantDirty bool
+	}{
+		{"no selector matches everything, room left", nil, nil, false, true},
+		{"matching selector, room left", map[string]string{"app": "web"}, map[string]string{"app": "web"}, false, true},
+		{"non-matching selector is skipped entirely", map[string]string{"app": "web"}, map[string]string{"app": "db"}, false, false},
+	}
+
+	for _, item := range table {
+		input := controllerWithSecretsMax(5, 4, item.selector)
+		secret := &api.Secret{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "sec", Labels: item.labels}}
+
+		dirty, err := admissionFunc(newSecretAttributes(secret), input, &api.ResourceObservation{Status: input.Status}, nil)
+		if (err != nil) != item.wantErr {
+			t.Errorf("%s: admissionFunc() error = %v, wantErr %v", item.name, err, item.wantErr)
+		}
+		if dirty != item.wantDirty {
+			t.Errorf("%s: admissionFunc() dirty = %v, want %v", item.name, dirty, item.wantDirty)
+		}
+	}
+}
+
+// TestAdmissionFuncDeniesAtMaxSecrets verifies admissionFunc rejects a Secret once the namespace
+// is already at its allowed max.
+func TestAdmissionFuncDeniesAtMaxSecrets(t *testing.T) {
+	input := controllerWithSecretsMax(1, 1, nil)
+	secret := &api.Secret{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "sec"}}
+
+	if _, err := admissionFunc(newSecretAttributes(secret), input, &api.ResourceObservation{}, nil); err == nil {
+		t.Errorf("expected an error admitting a Secret once the namespace is already at its max")
+	}
+}
+
+// TestAdmissionFuncConfigMaps verifies admissionFunc enforces the ConfigMap quota independently of
+// the Secret quota, honoring spec.Selector the same way.
+func TestAdmissionFuncConfigMaps(t *testing.T) {
+	table := []struct {
+		name      string
+		selector  map[string]string
+		labels    map[string]string
+		wantErr   bool
+		wantDirty bool
+	}{
+		{"no selector matches everything, room left", nil, nil, false, true},
+		{"matching selector, room left", map[string]string{"app": "web"}, map[string]string{"app": "web"}, false, true},
+		{"non-matching selector is skipped entirely", map[string]string{"app": "web"}, map[string]string{"app": "db"}, false, false},
+	}
+
+	for _, item := range table {
+		input := controllerWithConfigMapsMax(5, 4, item.selector)
+		configMap := &api.ConfigMap{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "cm", Labels: item.labels}}
+
+		dirty, err := admissionFunc(newConfigMapAttributes(configMap), input, &api.ResourceObservation{Status: input.Status}, nil)
+		if (err != nil) != item.wantErr {
+			t.Errorf("%s: admissionFunc() error = %v, wantErr %v", item.name, err, item.wantErr)
+		}
+		if dirty != item.wantDirty {
+			t.Errorf("%s: admissionFunc() dirty = %v, want %v", item.name, dirty, item.wantDirty)
+		}
+	}
+}
+
+// TestAdmissionFuncDeniesAtMaxConfigMaps verifies admissionFunc rejects a ConfigMap once the
+// namespace is already at its allowed max.
+func TestAdmissionFuncDeniesAtMaxConfigMaps(t *testing.T) {
+	input := controllerWithConfigMapsMax(1, 1, nil)
+	configMap := &api.ConfigMap{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "cm"}}
+
+	if _, err := admissionFunc(newConfigMapAttributes(configMap), input, &api.ResourceObservation{}, nil); err == nil {
+		t.Errorf("expected an error admitting a ConfigMap once the namespace is already at its max")
+	}
+}
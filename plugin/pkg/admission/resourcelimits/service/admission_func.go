@@ -0,0 +1,113 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/admission/resourcelimits"
+)
+
+func init() {
+	resourcelimits.RegisterAdmissionFunc("ResourceLimitsService", admissionFunc)
+}
+
+var resourceNameToMessage = map[api.ResourceName]string{
+	"Services":      "Limited to %v services in namespace %v",
+	"LoadBalancers": "Limited to %v load balancer services in namespace %v",
+	"NodePorts":     "Limited to %v node ports in namespace %v",
+}
+
+func makeObservation(status *api.ResourceControllerStatus, resourceName api.ResourceName, newQuantity *resource.Quantity) {
+	_, observedAllocatedByGroup := resourcecontroller.AllowedAndAllocated(status)
+	observedAllocatedGroupRules := observedAllocatedByGroup[api.ResourceControllerGroupByService]
+	observedAllocatedGroupRulesMax := observedAllocatedGroupRules[api.ResourceControllerRuleTypeMax]
+	observedAllocatedGroupRulesMax[resourceName] = *newQuantity
+}
+
+func serviceDelta(service *api.Service, resourceName api.ResourceName) int64 {
+	switch resourceName {
+	case "Services":
+		return 1
+	case "LoadBalancers":
+		if service.Spec.Type == api.ServiceTypeLoadBalancer {
+			return 1
+		}
+	case "NodePorts":
+		count := int64(0)
+		for _, port := range service.Spec.Ports {
+			if port.NodePort != 0 {
+				count++
+			}
+		}
+		return count
+	}
+	return 0
+}
+
+func admissionFunc(a admission.Attributes, input *api.ResourceController, observation *api.ResourceObservation, client client.Interface) (bool, error) {
+	dirty := false
+
+	if a.GetOperation() != "CREATE" {
+		return dirty, nil
+	}
+
+	if a.GetKind() != "services" {
+		return dirty, nil
+	}
+
+	allowedByGroup, allocatedByGroup := resourcecontroller.AllowedAndAllocated(&input.Status)
+	allowedGroupRulesMax := allowedByGroup[api.ResourceControllerGroupByService][api.ResourceControllerRuleTypeMax]
+	if allowedGroupRulesMax == nil {
+		return dirty, nil
+	}
+	allocatedGroupRulesMax := allocatedByGroup[api.ResourceControllerGroupByService][api.ResourceControllerRuleTypeMax]
+
+	service := a.GetObject().(*api.Service)
+
+	if !resourcecontroller.LabelsMatchSelector(service.Labels, input.Spec.Selector) {
+		return dirty, nil
+	}
+
+	for resourceName, limit := range allowedGroupRulesMax {
+		delta := serviceDelta(service, resourceName)
+		if delta == 0 {
+			continue
+		}
+
+		observed, observationExists := allocatedGroupRulesMax[resourceName]
+		if !observationExists {
+			return dirty, apierrors.NewForbidden(a.GetKind(), service.Name, fmt.Errorf("Unable to admit resource, waiting for resource observation to complete."))
+		}
+
+		if observed.Value()+delta > limit.Value() {
+			return dirty, apierrors.NewForbidden(a.GetKind(), service.Name,
+				fmt.Errorf(resourceNameToMessage[resourceName], limit.String(), input.Namespace))
+		}
+
+		makeObservation(&observation.Status, resourceName, resource.NewQuantity(observed.Value()+delta, resource.DecimalSI))
+		dirty = true
+	}
+
+	return dirty, nil
+}
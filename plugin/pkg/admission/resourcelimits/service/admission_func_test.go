@@ -0,0 +1,145 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/admission"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeAttributes is a minimal admission.Attributes for driving admissionFunc in tests.
+type fakeAttributes struct {
+	namespace string
+	kind      string
+	operation string
+	object    runtime.Object
+}
+
+func (f fakeAttributes) GetNamespace() string      { return f.namespace }
+func (f fakeAttributes) GetKind() string           { return f.kind }
+func (f fakeAttributes) GetOperation() string      { return f.operation }
+func (f fakeAttributes) GetObject() runtime.Object { return f.object }
+
+func newServiceAttributes(service *api.Service) admission.Attributes {
+	return fakeAttributes{
+		namespace: service.Namespace,
+		kind:      "services",
+		operation: "CREATE",
+		object:    service,
+	}
+}
+
+func controllerWithServicesMax(max, allocated int64, selector map[string]string) *api.ResourceController {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByService,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"Services": *resource.NewQuantity(max, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByService,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"Services": *resource.NewQuantity(allocated, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+	ctrl.Spec.Selector = selector
+	return ctrl
+}
+
+// TestAdmissionFunc verifies admissionFunc only charges a Service against the quota when it's
+// selected by the controller's spec.Selector, and is a no-op for one that isn't.
+func TestAdmissionFunc(t *testing.T) {
+	table := []struct {
+		name      string
+		selector  map[string]string
+		labels    map[string]string
+		wantErr   bool
+		wantDirty bool
+	}{
+		{"no selector matches everything, room left", nil, nil, false, true},
+		{"matching selector, room left", map[string]string{"app": "web"}, map[string]string{"app": "web"}, false, true},
+		{"non-matching selector is skipped entirely", map[string]string{"app": "web"}, map[string]string{"app": "db"}, false, false},
+	}
+
+	for _, item := range table {
+		input := controllerWithServicesMax(5, 4, item.selector)
+		service := &api.Service{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "svc", Labels: item.labels}}
+
+		dirty, err := admissionFunc(newServiceAttributes(service), input, &api.ResourceObservation{Status: input.Status}, nil)
+		if (err != nil) != item.wantErr {
+			t.Errorf("%s: admissionFunc() error = %v, wantErr %v", item.name, err, item.wantErr)
+		}
+		if dirty != item.wantDirty {
+			t.Errorf("%s: admissionFunc() dirty = %v, want %v", item.name, dirty, item.wantDirty)
+		}
+	}
+}
+
+// TestAdmissionFuncDeniesAtMax verifies admissionFunc rejects a Service once the namespace is
+// already at its allowed max.
+func TestAdmissionFuncDeniesAtMax(t *testing.T) {
+	input := controllerWithServicesMax(1, 1, nil)
+	service := &api.Service{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "svc"}}
+
+	if _, err := admissionFunc(newServiceAttributes(service), input, &api.ResourceObservation{}, nil); err == nil {
+		t.Errorf("expected an error admitting a Service once the namespace is already at its max")
+	}
+}
+
+// TestAdmissionFuncLoadBalancers verifies the LoadBalancers resource only counts services of
+// type LoadBalancer.
+func TestAdmissionFuncLoadBalancers(t *testing.T) {
+	ctrl := &api.ResourceController{
+		Status: api.ResourceControllerStatus{
+			Allowed: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByService,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"LoadBalancers": *resource.NewQuantity(1, resource.DecimalSI),
+				},
+			}},
+			Allocated: []api.ResourceControllerGroup{{
+				GroupBy:  api.ResourceControllerGroupByService,
+				RuleType: api.ResourceControllerRuleTypeMax,
+				Resources: api.ResourceList{
+					"LoadBalancers": *resource.NewQuantity(0, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+
+	clusterIP := &api.Service{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "clusterip"}}
+	if dirty, err := admissionFunc(newServiceAttributes(clusterIP), ctrl, &api.ResourceObservation{}, nil); err != nil || dirty {
+		t.Errorf("admissionFunc() for a non-LoadBalancer service = (%v, %v), want (false, nil)", dirty, err)
+	}
+
+	lb := &api.Service{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "lb"}}
+	lb.Spec.Type = api.ServiceTypeLoadBalancer
+	if dirty, err := admissionFunc(newServiceAttributes(lb), ctrl, &api.ResourceObservation{Status: ctrl.Status}, nil); err != nil || !dirty {
+		t.Errorf("admissionFunc() for a LoadBalancer service = (%v, %v), want (true, nil)", dirty, err)
+	}
+}
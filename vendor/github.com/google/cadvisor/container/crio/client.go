@@ -0,0 +1,78 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crio
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	runtimeapi "k8s.io/kubernetes/pkg/kubelet/apis/cri/v1alpha1/runtime"
+
+	"google.golang.org/grpc"
+)
+
+// dialTimeout bounds how long dialing the CRI-O runtime socket is allowed to take, matching the
+// timeout the kubelet's own CRI remote runtime dialer uses.
+const dialTimeout = 10 * time.Second
+
+// crioClient is the subset of the CRI RuntimeService cadvisor needs in order to confirm a cgroup
+// really belongs to crio and to label its metrics with the container's Kubernetes metadata.
+type crioClient interface {
+	ContainerStatus(id string) (*runtimeapi.ContainerStatus, error)
+	ListContainers() ([]*runtimeapi.Container, error)
+	Version() (*runtimeapi.VersionResponse, error)
+}
+
+// grpcCrioClient implements crioClient against CRI-O's CRI runtime gRPC socket.
+type grpcCrioClient struct {
+	runtimeClient runtimeapi.RuntimeServiceClient
+}
+
+// newCrioClient dials endpoint (a unix:// CRI-O runtime socket, e.g. ArgCrioEndpoint) and returns a
+// crioClient backed by the resulting gRPC connection.
+func newCrioClient(endpoint string) (crioClient, error) {
+	addr := strings.TrimPrefix(endpoint, "unix://")
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithDialer(dialUnix), grpc.WithTimeout(dialTimeout))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcCrioClient{runtimeClient: runtimeapi.NewRuntimeServiceClient(conn)}, nil
+}
+
+func dialUnix(addr string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", addr, timeout)
+}
+
+func (c *grpcCrioClient) ContainerStatus(id string) (*runtimeapi.ContainerStatus, error) {
+	resp, err := c.runtimeClient.ContainerStatus(context.Background(), &runtimeapi.ContainerStatusRequest{ContainerId: id})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+func (c *grpcCrioClient) ListContainers() ([]*runtimeapi.Container, error) {
+	resp, err := c.runtimeClient.ListContainers(context.Background(), &runtimeapi.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Containers, nil
+}
+
+func (c *grpcCrioClient) Version() (*runtimeapi.VersionResponse, error) {
+	return c.runtimeClient.Version(context.Background(), &runtimeapi.VersionRequest{})
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crio
+
+import (
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultConfigPath is where crio installs its configuration file, mirroring libkpod/config.go's
+// default in the crio-o/crio-o project itself.
+const DefaultConfigPath = "/etc/crio/crio.conf"
+
+// crioConfig mirrors the subset of libkpod/config.go's [crio.runtime], [crio.image], and
+// [crio.network] sections that the cadvisor handler needs in order to find a container's storage,
+// logs, and cgroup layout without guessing at compiled-in defaults.
+type crioConfig struct {
+	Crio struct {
+		Runtime struct {
+			// Root is the storage root crio was launched with (CONTAINER_ROOT, libkpod default
+			// /var/lib/containers/storage).
+			Root string `toml:"root"`
+			// RunRoot is the runtime state root (e.g. /var/run/containers/storage).
+			RunRoot string `toml:"runroot"`
+			// StorageDriver is the container storage driver, e.g. "overlay", "overlay2", "devicemapper".
+			StorageDriver string `toml:"storage_driver"`
+			// StorageOption holds the raw "driver_option=value" strings passed to containers/storage.
+			StorageOption []string `toml:"storage_option"`
+			// CgroupManager is either "cgroupfs" or "systemd"; it determines the shape of the cgroup
+			// path crio creates for a container (e.g. "crio-<id>.scope" under systemd).
+			CgroupManager string `toml:"cgroup_manager"`
+			// PidsLimit is the maximum number of process IDs allowed in a container's pid cgroup.
+			PidsLimit int64 `toml:"pids_limit"`
+			// LogDir is the default directory crio writes a container's log file under, when the pod
+			// doesn't otherwise specify a log path.
+			LogDir string `toml:"log_dir"`
+		} `toml:"runtime"`
+		Image struct {
+			// ImageVolumes controls how a built-in image VOLUME is handled ("bind", "tmpfs", "ignore").
+			ImageVolumes string `toml:"image_volumes"`
+		} `toml:"image"`
+		Network struct {
+			// NetworkDir holds CNI configuration consumed for pod sandbox networking.
+			NetworkDir string `toml:"network_dir"`
+		} `toml:"network"`
+	} `toml:"crio"`
+}
+
+// defaultCrioConfig returns the crioConfig this factory falls back to when DefaultConfigPath can't be
+// read, matching the hardcoded values this factory used before it read crio.conf.
+func defaultCrioConfig() *crioConfig {
+	config := &crioConfig{}
+	config.Crio.Runtime.Root = RootDir()
+	config.Crio.Runtime.StorageDriver = string(overlayStorageDriver)
+	config.Crio.Runtime.CgroupManager = string(cgroupManagerCgroupfs)
+	return config
+}
+
+// loadConfig decodes the crio TOML configuration at path. Callers should fall back to
+// defaultCrioConfig on error rather than fail factory registration outright, since a missing or
+// unreadable crio.conf shouldn't prevent cadvisor from handling crio containers at all.
+func loadConfig(path string) (*crioConfig, error) {
+	config := &crioConfig{}
+	if _, err := toml.DecodeFile(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
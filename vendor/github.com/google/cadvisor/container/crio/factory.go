@@ -28,6 +28,8 @@ import (
 	info "github.com/google/cadvisor/info/v1"
 	"github.com/google/cadvisor/manager/watcher"
 
+	runtimeapi "k8s.io/kubernetes/pkg/kubelet/apis/cri/v1alpha1/runtime"
+
 	"github.com/golang/glog"
 )
 
@@ -36,10 +38,30 @@ var ArgCrioEndpoint = flag.String("crio", "unix:///var/run/crio.sock", "crio end
 // The namespace under which crio aliases are unique.
 const CrioNamespace = "crio"
 
-// Regexp that identifies docker cgroups, containers started with
-// --cgroup-parent have another prefix than 'docker'
+// cgroupManager names the two cgroup manager values libpod/crio.conf's cgroup_manager accepts.
+// Each manager lays out a container's cgroup path differently, so CanHandleAndAccept must match
+// against the manager the node is actually configured with rather than a single regexp.
+type cgroupManager string
+
+const (
+	cgroupManagerCgroupfs cgroupManager = "cgroupfs"
+	cgroupManagerSystemd  cgroupManager = "systemd"
+)
+
+// Regexp that identifies a cgroupfs-managed crio container, e.g. .../crio-<64-char-id>.
 var crioCgroupRegexp = regexp.MustCompile(`([a-z0-9]{64})`)
 
+// Regexp that identifies a systemd-managed crio container, e.g. crio-<64-char-id>.scope.
+var crioSystemdCgroupRegexp = regexp.MustCompile(`crio-([a-z0-9]{64})\.scope`)
+
+// cgroupRegexpFor returns the cgroup name matcher appropriate for manager.
+func cgroupRegexpFor(manager cgroupManager) *regexp.Regexp {
+	if manager == cgroupManagerSystemd {
+		return crioSystemdCgroupRegexp
+	}
+	return crioCgroupRegexp
+}
+
 var (
 	// Basepath to all container specific information that libcontainer stores.
 	crioRootDir string
@@ -68,6 +90,12 @@ type crioFactory struct {
 
 	storageDriver storageDriver
 	storageDir    string
+	logDir        string
+	cgroupManager cgroupManager
+
+	// crioClient talks to the CRI-O runtime over its CRI gRPC socket, and is used to confirm a
+	// candidate cgroup really belongs to crio and to fetch a container's Kubernetes metadata.
+	crioClient crioClient
 
 	// Information about the mounted cgroup subsystems.
 	cgroupSubsystems libcontainer.CgroupSubsystems
@@ -83,9 +111,14 @@ func (self *crioFactory) String() string {
 }
 
 func (self *crioFactory) NewContainerHandler(name string, inHostNamespace bool) (handler container.ContainerHandler, err error) {
-	// TODO if we have a crio-client, configure it here
-	// TODO are there any env vars we need to white list, if so, do it here...
 	metadataEnvs := []string{}
+	if self.crioClient != nil {
+		if status, statusErr := self.crioClient.ContainerStatus(ContainerNameToCrioId(name)); statusErr == nil {
+			metadataEnvs = whitelistedContainerEnvs(status)
+		} else {
+			glog.V(4).Infof("crio: unable to fetch container status for %v, metrics will not be labeled with pod metadata: %v", name, statusErr)
+		}
+	}
 	handler, err = newCrioContainerHandler(
 		name,
 		self.machineInfoFactory,
@@ -100,6 +133,39 @@ func (self *crioFactory) NewContainerHandler(name string, inHostNamespace bool)
 	return
 }
 
+// metadataEnvWhitelist lists the CRI-O labels/annotations passed through to the handler so its
+// metrics can be labeled with the Kubernetes pod/container metadata an operator is likely to want,
+// without leaking arbitrary container labels into cadvisor's metric labels. These are the
+// io.kubernetes.* keys the kubelet's CRI-O-backed dockershim sets on every container it creates.
+var metadataEnvWhitelist = []string{
+	"io.kubernetes.pod.name",
+	"io.kubernetes.pod.namespace",
+	"io.kubernetes.pod.uid",
+	"io.kubernetes.container.name",
+}
+
+// whitelistedContainerEnvs returns the subset of status's labels and annotations that appears in
+// metadataEnvWhitelist, in "KEY=VALUE" form, suitable for the metadataEnvs newCrioContainerHandler expects.
+func whitelistedContainerEnvs(status *runtimeapi.ContainerStatus) []string {
+	allowed := make(map[string]bool, len(metadataEnvWhitelist))
+	for _, key := range metadataEnvWhitelist {
+		allowed[key] = true
+	}
+
+	envs := []string{}
+	for key, value := range status.GetLabels() {
+		if allowed[key] {
+			envs = append(envs, key+"="+value)
+		}
+	}
+	for key, value := range status.GetAnnotations() {
+		if allowed[key] {
+			envs = append(envs, key+"="+value)
+		}
+	}
+	return envs
+}
+
 // Returns the CRIO ID from the full container name.
 func ContainerNameToCrioId(name string) string {
 	id := path.Base(name)
@@ -107,36 +173,43 @@ func ContainerNameToCrioId(name string) string {
 	if matches := crioCgroupRegexp.FindStringSubmatch(id); matches != nil {
 		return matches[1]
 	}
+	if matches := crioSystemdCgroupRegexp.FindStringSubmatch(id); matches != nil {
+		return matches[1]
+	}
 
 	return id
 }
 
-// isContainerName returns true if the cgroup with associated name
-// corresponds to a crio container.
-func isContainerName(name string) bool {
+// isContainerName returns true if the cgroup with associated name corresponds to a crio container
+// managed by the given cgroup manager.
+func isContainerName(manager cgroupManager, name string) bool {
 	// always ignore .mount cgroup even if associated with crio and delegate to systemd
 	if strings.HasSuffix(name, ".mount") {
 		return false
 	}
-	return crioCgroupRegexp.MatchString(path.Base(name))
+	return cgroupRegexpFor(manager).MatchString(path.Base(name))
 }
 
 // crio handles all containers under /crio
 func (self *crioFactory) CanHandleAndAccept(name string) (bool, bool, error) {
-	glog.Infof("CRIO CAN HANDLE AND ACCEPT: %v", name)
-	if strings.HasPrefix(path.Base(name), "crio-conman") {
-		glog.Info("SKIPPING CRIO-CONMON")
-	}
 	if !strings.HasPrefix(path.Base(name), CrioNamespace) {
 		return false, false, nil
 	}
-	// if the container is not associated with docker, we can't handle it or accept it.
-	if !isContainerName(name) {
+	// if the cgroup name doesn't match the shape the node's configured cgroup manager produces,
+	// we can't handle it or accept it.
+	if !isContainerName(self.cgroupManager, name) {
 		return false, false, nil
 	}
-	glog.Infof("CRIO HANDLE AND ACCEPT: %v", name)
-	// TODO should we call equivalent of a crio info to be sure its really ours
-	// and to know if the container is running...
+
+	// confirm the container really belongs to crio, rather than merely matching the cgroup regexp,
+	// by asking the CRI-O runtime about it directly.
+	if self.crioClient != nil {
+		if _, err := self.crioClient.ContainerStatus(ContainerNameToCrioId(name)); err != nil {
+			glog.V(4).Infof("crio: %v matched the crio cgroup pattern but the runtime doesn't recognize it, skipping: %v", name, err)
+			return false, false, nil
+		}
+	}
+
 	return true, true, nil
 }
 
@@ -153,26 +226,46 @@ var (
 
 // Register root container before running this function!
 func Register(factory info.MachineInfoFactory, fsInfo fs.FsInfo, ignoreMetrics container.MetricSet) error {
-	// TODO initialize any client we will use to speak to crio
-	// runcom mrunal -- ideally, we read /etc/crio/crio.conf here so we know how machine is configured
-	// i.e. what is the storage driver, etc.
-	// TODO determine crio version so we can work differently w/ future versions if needed
 	cgroupSubsystems, err := libcontainer.GetCgroupSubsystems()
 	if err != nil {
 		return fmt.Errorf("failed to get cgroup subsystems: %v", err)
 	}
 
-	// TODO: FIX ME mrunal / runcom so this is read from crio.conf
-	storageDriver := overlayStorageDriver
-	storageDir := RootDir()
+	config, err := loadConfig(DefaultConfigPath)
+	if err != nil {
+		glog.Warningf("crio: unable to read %v, falling back to defaults: %v", DefaultConfigPath, err)
+		config = defaultCrioConfig()
+	}
+
+	storageDriver := storageDriver(config.Crio.Runtime.StorageDriver)
+	if storageDriver == "" {
+		storageDriver = overlayStorageDriver
+	}
+	storageDir := config.Crio.Runtime.Root
+	if storageDir == "" {
+		storageDir = RootDir()
+	}
+	cgroupMgr := cgroupManager(config.Crio.Runtime.CgroupManager)
+	if cgroupMgr == "" {
+		cgroupMgr = cgroupManagerCgroupfs
+	}
+
+	client, err := newCrioClient(*ArgCrioEndpoint)
+	if err != nil {
+		glog.Warningf("crio: unable to connect to the crio runtime at %v, containers will be handled without pod metadata: %v", *ArgCrioEndpoint, err)
+		client = nil
+	}
 
-	glog.Infof("Registering CRI-O factory")
+	glog.Infof("Registering CRI-O factory (storageDriver=%v storageDir=%v cgroupManager=%v)", storageDriver, storageDir, cgroupMgr)
 	f := &crioFactory{
 		cgroupSubsystems:   cgroupSubsystems,
 		fsInfo:             fsInfo,
 		machineInfoFactory: factory,
 		storageDriver:      storageDriver,
 		storageDir:         storageDir,
+		logDir:             config.Crio.Runtime.LogDir,
+		cgroupManager:      cgroupMgr,
+		crioClient:         client,
 		ignoreMetrics:      ignoreMetrics,
 	}
 